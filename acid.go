@@ -0,0 +1,89 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+)
+
+var tokenAcid = [4]byte{'a', 'c', 'i', 'd'}
+
+// Flags of the acid chunk
+const (
+	AcidOneShot    uint32 = 0x01 // the file is no loop
+	AcidRootNote   uint32 = 0x02 // RootNote is set
+	AcidStretch    uint32 = 0x04
+	AcidDiskBased  uint32 = 0x08
+	AcidHighOctave uint32 = 0x10
+)
+
+// Acid is the acid chunk with the tempo and key of loops, used by DAWs to sync them
+type Acid struct {
+	Flags            uint32
+	RootNote         uint16 // MIDI note, 60 is C4
+	Beats            uint32
+	MeterDenominator uint16
+	MeterNumerator   uint16
+	Tempo            float32 // in beats per minute
+}
+
+// 24 byte acid chunk
+type acidChunk struct {
+	Flags            uint32
+	RootNote         uint16
+	Unknown1         uint16
+	Unknown2         float32
+	Beats            uint32
+	MeterDenominator uint16
+	MeterNumerator   uint16
+	Tempo            float32
+}
+
+// GetAcid returns the acid chunk, nil if the file has none
+func (wav *Reader) GetAcid() *Acid {
+	return wav.acid
+}
+
+// parseChunkAcid reads an acid chunk of chunkSize bytes, shorter and truncated chunks are ignored
+func (wav *Reader) parseChunkAcid(chunkSize uint32) error {
+	pos, err := wav.input.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return err
+	}
+	if chunkSize < 24 || int64(chunkSize) > wav.size-pos {
+		return nil
+	}
+
+	var c acidChunk
+	if err = binary.Read(wav.input, wav.order, &c); err != nil {
+		return err
+	}
+
+	wav.acid = &Acid{
+		Flags:            c.Flags,
+		RootNote:         c.RootNote,
+		Beats:            c.Beats,
+		MeterDenominator: c.MeterDenominator,
+		MeterNumerator:   c.MeterNumerator,
+		Tempo:            c.Tempo,
+	}
+	return nil
+}
+
+// chunk returns the acid chunk, the unknown fields get the values Acidizer writes
+func (a *Acid) chunk(order binary.ByteOrder) []byte {
+	var body bytes.Buffer
+	binary.Write(&body, order, acidChunk{
+		Flags:            a.Flags,
+		RootNote:         a.RootNote,
+		Unknown1:         0x8000,
+		Beats:            a.Beats,
+		MeterDenominator: a.MeterDenominator,
+		MeterNumerator:   a.MeterNumerator,
+		Tempo:            a.Tempo,
+	})
+
+	var b bytes.Buffer
+	writeChunk(&b, order, tokenAcid, body.Bytes())
+	return b.Bytes()
+}
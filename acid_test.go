@@ -0,0 +1,93 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestParseAcid(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var c bytes.Buffer
+	writeChunk(&c, binary.LittleEndian, tokenAcid, []byte{
+		0x06, 0, 0, 0, // root note and stretch
+		0x39, 0, // A3
+		0, 0x80,
+		0, 0, 0, 0,
+		8, 0, 0, 0, // beats
+		4, 0, 4, 0, // 4/4
+		0, 0, 0xf0, 0x42, // 120 bpm
+	})
+	buf := infoWave(c.Bytes())
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(&Acid{
+		Flags:            AcidRootNote | AcidStretch,
+		RootNote:         57,
+		Beats:            8,
+		MeterDenominator: 4,
+		MeterNumerator:   4,
+		Tempo:            120,
+	}, rd.GetAcid())
+	is.Equal(rd.GetAcid(), rd.GetFile().Acid)
+
+	// too short
+	c.Reset()
+	writeChunk(&c, binary.LittleEndian, tokenAcid, make([]byte, 20))
+	buf = infoWave(c.Bytes())
+	rd, err = NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Nil(rd.GetAcid())
+
+	// truncated
+	buf = infoWave(append([]byte("acid\x18\x00\x00\x00"), make([]byte, 12)...))
+	rd, err = NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Nil(rd.GetAcid())
+}
+
+func TestWriteReadAcid(t *testing.T) {
+	is := is.New(t)
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+	defer os.Remove(f.Name())
+
+	acid := &Acid{
+		Flags:            AcidOneShot | AcidRootNote,
+		RootNote:         60,
+		Beats:            2,
+		MeterDenominator: 8,
+		MeterNumerator:   6,
+		Tempo:            97.5,
+	}
+	meta := File{
+		Channels:        1,
+		SampleRate:      44100,
+		SignificantBits: 16,
+		Acid:            acid,
+	}
+	wr, err := meta.NewWriter(f)
+	is.NoErr(err)
+	is.NoErr(wr.WriteInt32(3))
+	is.NoErr(wr.Close())
+
+	buf, err := ioutil.ReadFile(f.Name())
+	is.NoErr(err)
+	i := bytes.Index(buf, tokenAcid[:])
+	is.Equal(uint32(24), binary.LittleEndian.Uint32(buf[i+4:]))
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(acid, rd.GetAcid())
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(3), s)
+}
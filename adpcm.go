@@ -0,0 +1,244 @@
+package wav
+
+// blockDecoder decodes one block of a block based compressed format
+// into interleaved 16 bit samples
+type blockDecoder interface {
+	// blockFrames returns the number of sample frames in a block of size bytes
+	blockFrames(size int) int
+	decodeBlock(block []byte, out []int16) ([]int16, error)
+}
+
+var (
+	imaIndexTable = [16]int{
+		-1, -1, -1, -1, 2, 4, 6, 8,
+		-1, -1, -1, -1, 2, 4, 6, 8,
+	}
+
+	imaStepTable = [89]int{
+		7, 8, 9, 10, 11, 12, 13, 14, 16, 17,
+		19, 21, 23, 25, 28, 31, 34, 37, 41, 45,
+		50, 55, 60, 66, 73, 80, 88, 97, 107, 118,
+		130, 143, 157, 173, 190, 209, 230, 253, 279, 307,
+		337, 371, 408, 449, 494, 544, 598, 658, 724, 796,
+		876, 963, 1060, 1166, 1282, 1411, 1552, 1707, 1878, 2066,
+		2272, 2499, 2749, 3024, 3327, 3660, 4026, 4428, 4871, 5358,
+		5894, 6484, 7132, 7845, 8630, 9493, 10442, 11487, 12635, 13899,
+		15289, 16818, 18500, 20350, 22385, 24623, 27086, 29794, 32767,
+	}
+)
+
+// imaDecoder decodes IMA/DVI ADPCM blocks.
+// Each block starts with a 4 byte header per channel, holding the first sample and step index.
+// The nibbles follow in groups of 4 bytes per channel, low nibble first.
+type imaDecoder struct {
+	channels        int
+	samplesPerBlock int
+}
+
+// imaSamplesPerBlock calculates the frames of a block, if the fmt chunk doesn't tell us
+func imaSamplesPerBlock(blockAlign, channels int) int {
+	return (blockAlign-4*channels)*2/channels + 1
+}
+
+type imaChannel struct {
+	predictor int
+	index     int
+}
+
+func (c *imaChannel) decode(nibble byte) int16 {
+	step := imaStepTable[c.index]
+
+	diff := step >> 3
+	if nibble&1 != 0 {
+		diff += step >> 2
+	}
+	if nibble&2 != 0 {
+		diff += step >> 1
+	}
+	if nibble&4 != 0 {
+		diff += step
+	}
+	if nibble&8 != 0 {
+		c.predictor -= diff
+	} else {
+		c.predictor += diff
+	}
+
+	if c.predictor > 32767 {
+		c.predictor = 32767
+	} else if c.predictor < -32768 {
+		c.predictor = -32768
+	}
+
+	c.index += imaIndexTable[nibble]
+	if c.index < 0 {
+		c.index = 0
+	} else if c.index > 88 {
+		c.index = 88
+	}
+
+	return int16(c.predictor)
+}
+
+func (d imaDecoder) blockFrames(size int) int {
+	if size < 4*d.channels {
+		return 0
+	}
+	// the last block may be short
+	frames := imaSamplesPerBlock(size, d.channels)
+	if frames > d.samplesPerBlock {
+		frames = d.samplesPerBlock
+	}
+	return frames
+}
+
+func (d imaDecoder) decodeBlock(block []byte, out []int16) ([]int16, error) {
+	headerSize := 4 * d.channels
+	if len(block) < headerSize {
+		return nil, ErrBrokenBlock
+	}
+
+	frames := d.blockFrames(len(block))
+	if cap(out) < frames*d.channels {
+		out = make([]int16, frames*d.channels)
+	}
+	out = out[:frames*d.channels]
+
+	state := make([]imaChannel, d.channels)
+	for c := range state {
+		h := block[4*c:]
+		state[c].predictor = int(int16(uint16(h[0]) | uint16(h[1])<<8))
+		state[c].index = int(h[2])
+		if state[c].index > 88 {
+			return nil, ErrBrokenBlock
+		}
+		out[c] = int16(state[c].predictor)
+	}
+
+	pos := headerSize
+	for frame := 1; frame < frames; frame += 8 {
+		for c := range state {
+			for k := 0; k < 8 && pos < len(block); k += 2 {
+				b := block[pos]
+				pos++
+				if frame+k < frames {
+					out[(frame+k)*d.channels+c] = state[c].decode(b & 0x0F)
+				}
+				if frame+k+1 < frames {
+					out[(frame+k+1)*d.channels+c] = state[c].decode(b >> 4)
+				}
+			}
+		}
+	}
+
+	return out, nil
+}
+
+var msAdaptationTable = [16]int{
+	230, 230, 230, 230, 307, 409, 512, 614,
+	768, 614, 512, 409, 307, 230, 230, 230,
+}
+
+// msDecoder decodes Microsoft ADPCM blocks.
+// The block header holds per channel the predictor index, delta and the first two samples.
+// The nibbles follow interleaved by channel, high nibble first.
+type msDecoder struct {
+	channels        int
+	samplesPerBlock int
+	coefs           [][2]int
+}
+
+// msSamplesPerBlock calculates the frames of a block, if the fmt chunk doesn't tell us
+func msSamplesPerBlock(blockAlign, channels int) int {
+	return (blockAlign-7*channels)*2/channels + 2
+}
+
+type msChannel struct {
+	coef         [2]int
+	delta        int
+	samp1, samp2 int
+}
+
+func (c *msChannel) decode(nibble byte) int16 {
+	signed := int(nibble)
+	if signed >= 8 {
+		signed -= 16
+	}
+
+	predictor := (c.samp1*c.coef[0]+c.samp2*c.coef[1])>>8 + signed*c.delta
+	if predictor > 32767 {
+		predictor = 32767
+	} else if predictor < -32768 {
+		predictor = -32768
+	}
+
+	c.samp2 = c.samp1
+	c.samp1 = predictor
+
+	c.delta = (msAdaptationTable[nibble] * c.delta) >> 8
+	if c.delta < 16 {
+		c.delta = 16
+	}
+
+	return int16(predictor)
+}
+
+func (d msDecoder) blockFrames(size int) int {
+	if size < 7*d.channels {
+		return 0
+	}
+	// the last block may be short
+	frames := msSamplesPerBlock(size, d.channels)
+	if frames > d.samplesPerBlock {
+		frames = d.samplesPerBlock
+	}
+	return frames
+}
+
+func (d msDecoder) decodeBlock(block []byte, out []int16) ([]int16, error) {
+	headerSize := 7 * d.channels
+	if len(block) < headerSize {
+		return nil, ErrBrokenBlock
+	}
+
+	frames := d.blockFrames(len(block))
+	if cap(out) < frames*d.channels {
+		out = make([]int16, frames*d.channels)
+	}
+	out = out[:frames*d.channels]
+
+	le16 := func(i int) int {
+		return int(int16(uint16(block[i]) | uint16(block[i+1])<<8))
+	}
+
+	state := make([]msChannel, d.channels)
+	for c := range state {
+		predictor := int(block[c])
+		if predictor >= len(d.coefs) {
+			return nil, ErrBrokenBlock
+		}
+		state[c].coef = d.coefs[predictor]
+		state[c].delta = le16(d.channels + 2*c)
+		state[c].samp1 = le16(3*d.channels + 2*c)
+		state[c].samp2 = le16(5*d.channels + 2*c)
+
+		// the older sample comes first
+		out[c] = int16(state[c].samp2)
+		if frames > 1 {
+			out[d.channels+c] = int16(state[c].samp1)
+		}
+	}
+
+	i := 2 * d.channels
+	for _, b := range block[headerSize:] {
+		for _, nibble := range [2]byte{b >> 4, b & 0x0F} {
+			if i >= len(out) {
+				return out, nil
+			}
+			out[i] = state[i%d.channels].decode(nibble)
+			i++
+		}
+	}
+
+	return out, nil
+}
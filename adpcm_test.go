@@ -0,0 +1,202 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestIMADecoder_mono(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	dec := imaDecoder{channels: 1, samplesPerBlock: 9}
+	out, err := dec.decodeBlock([]byte{100, 0, 10, 0, 0x17, 0x8f, 0x34, 0xc2}, nil)
+	is.NoErr(err)
+	is.Equal([]int16{100, 134, 149, 81, 71, 153, 230, 280, 198}, out)
+}
+
+func TestIMADecoder_stereo(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	dec := imaDecoder{channels: 2, samplesPerBlock: 9}
+	block := []byte{
+		0xfb, 0xff, 0, 0, // left: -5, index 0
+		0xd0, 0x07, 40, 0, // right: 2000, index 40
+		0x77, 0x77, 0x77, 0x77,
+		0x88, 0x88, 0x88, 0x88,
+	}
+	out, err := dec.decodeBlock(block, nil)
+	is.NoErr(err)
+	is.Equal([]int16{-5, 2000, 6, 1958, 36, 1920, 99, 1886, 235, 1855, 528, 1827, 1159, 1801, 2516, 1778, 5426, 1757}, out)
+}
+
+func TestIMADecoder_brokenIndex(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	dec := imaDecoder{channels: 1, samplesPerBlock: 9}
+	_, err := dec.decodeBlock([]byte{0, 0, 89, 0, 0, 0, 0, 0}, nil)
+	is.Equal(ErrBrokenBlock, err)
+}
+
+func imaFile(withFact bool) []byte {
+	var body bytes.Buffer
+	body.Write(wave)
+	body.Write(fmt20)
+	body.Write([]byte{0x14, 0x00, 0x00, 0x00}) // LengthOfHeader
+	body.Write([]byte{0x11, 0x00})             // AudioFormat
+	body.Write([]byte{0x01, 0x00})             // NumOfChannels
+	body.Write([]byte{0x40, 0x1f, 0x00, 0x00}) // SampleRate
+	body.Write([]byte{0x1c, 0x1c, 0x00, 0x00}) // BytesPerSec
+	body.Write([]byte{0x08, 0x00})             // BytesPerBloc
+	body.Write([]byte{0x04, 0x00})             // BitsPerSample
+	body.Write([]byte{0x02, 0x00})             // cbSize
+	body.Write([]byte{0x09, 0x00})             // SamplesPerBlock
+	if withFact {
+		body.WriteString("fact")
+		body.Write([]byte{0x04, 0x00, 0x00, 0x00})
+		body.Write([]byte{0x0c, 0x00, 0x00, 0x00}) // 12 frames
+	}
+	body.Write([]byte{0x64, 0x61, 0x74, 0x61}) // "data"
+	body.Write([]byte{0x10, 0x00, 0x00, 0x00})
+	body.Write([]byte{100, 0, 10, 0, 0x17, 0x8f, 0x34, 0xc2})
+	body.Write([]byte{0xfb, 0xff, 0, 0, 0x77, 0x77, 0x77, 0x77})
+
+	var b bytes.Buffer
+	b.Write(riff)
+	binary.Write(&b, binary.LittleEndian, uint32(body.Len()))
+	b.Write(body.Bytes())
+	return b.Bytes()
+}
+
+func TestReadSample_IMAADPCM(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	buf := imaFile(true)
+	wavReader, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(AudioFormatIMAADPCM, wavReader.GetAudioFormat())
+	is.Equal(uint64(12), wavReader.GetSampleCount())
+
+	var got []int32
+	for {
+		n, err := wavReader.ReadSample()
+		if err == io.EOF {
+			break
+		}
+		is.NoErr(err)
+		got = append(got, n)
+	}
+	is.Equal([]int32{100, 134, 149, 81, 71, 153, 230, 280, 198, -5, 6, 36}, got)
+
+	// start over and read the raw, decoded bytes
+	is.NoErr(wavReader.Reset())
+	raw, err := wavReader.ReadRawSample()
+	is.NoErr(err)
+	is.Equal([]byte{100, 0}, raw)
+}
+
+func TestReadSample_IMAADPCMNoFact(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	buf := imaFile(false)
+	wavReader, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	// without a fact chunk all blocks are assumed to be full
+	is.Equal(uint64(18), wavReader.GetSampleCount())
+	_, err = wavReader.ReadSampleEvery(2, 0)
+	is.Equal(ErrFormatNotSupported, err)
+}
+
+var msStandardCoefs = [][2]int{{256, 0}, {512, -256}, {0, 0}, {192, 64}, {240, 0}, {460, -208}, {392, -232}}
+
+func TestMSDecoder_mono(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	dec := msDecoder{channels: 1, samplesPerBlock: 8, coefs: msStandardCoefs}
+	block := []byte{
+		0,     // predictor
+		16, 0, // delta
+		100, 0, // sample 1
+		50, 0, // sample 2
+		0x1f, 0x72, 0x8e,
+	}
+	out, err := dec.decodeBlock(block, nil)
+	is.NoErr(err)
+	is.Equal([]int16{50, 100, 116, 100, 212, 288, 16, -188}, out)
+}
+
+func TestMSDecoder_stereo(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	dec := msDecoder{channels: 2, samplesPerBlock: 6, coefs: msStandardCoefs}
+	block := []byte{
+		1, 3, // predictors
+		20, 0, 0x2c, 0x01, // deltas
+		0xf6, 0xff, 0xe8, 0x03, // sample 1
+		0xec, 0xff, 0x84, 0x03, // sample 2
+		0x3c, 0x5a, 0x01, 0xf7,
+	}
+	out, err := dec.decodeBlock(block, nil)
+	is.NoErr(err)
+	is.Equal([]int16{-20, 900, -10, 1000, 60, -225, 215, -2073, 370, -893, 501, 3327}, out)
+}
+
+func TestMSDecoder_brokenPredictor(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	dec := msDecoder{channels: 1, samplesPerBlock: 8, coefs: msStandardCoefs}
+	_, err := dec.decodeBlock([]byte{7, 16, 0, 0, 0, 0, 0, 0, 0, 0}, nil)
+	is.Equal(ErrBrokenBlock, err)
+}
+
+func TestReadSample_MSADPCM(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var body bytes.Buffer
+	body.Write(wave)
+	body.Write(fmt20)
+	body.Write([]byte{0x32, 0x00, 0x00, 0x00}) // LengthOfHeader
+	body.Write([]byte{0x02, 0x00})             // AudioFormat
+	body.Write([]byte{0x01, 0x00})             // NumOfChannels
+	body.Write([]byte{0x22, 0x56, 0x00, 0x00}) // SampleRate
+	body.Write([]byte{0x00, 0x00, 0x00, 0x00}) // BytesPerSec
+	body.Write([]byte{0x0a, 0x00})             // BytesPerBloc
+	body.Write([]byte{0x04, 0x00})             // BitsPerSample
+	body.Write([]byte{0x20, 0x00})             // cbSize
+	body.Write([]byte{0x08, 0x00})             // SamplesPerBlock
+	body.Write([]byte{0x07, 0x00})             // NumCoef
+	for _, c := range msStandardCoefs {
+		binary.Write(&body, binary.LittleEndian, [2]int16{int16(c[0]), int16(c[1])})
+	}
+	body.WriteString("fact")
+	body.Write([]byte{0x04, 0x00, 0x00, 0x00})
+	body.Write([]byte{0x06, 0x00, 0x00, 0x00})
+	body.Write([]byte{0x64, 0x61, 0x74, 0x61}) // "data"
+	body.Write([]byte{0x0a, 0x00, 0x00, 0x00})
+	body.Write([]byte{0, 16, 0, 100, 0, 50, 0, 0x1f, 0x72, 0x8e})
+
+	var b bytes.Buffer
+	b.Write(riff)
+	binary.Write(&b, binary.LittleEndian, uint32(body.Len()))
+	b.Write(body.Bytes())
+
+	wavReader, err := NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	is.NoErr(err)
+	is.Equal(AudioFormatMSADPCM, wavReader.GetAudioFormat())
+	is.Equal(uint64(6), wavReader.GetSampleCount())
+
+	var got []int32
+	for {
+		n, err := wavReader.ReadSample()
+		if err == io.EOF {
+			break
+		}
+		is.NoErr(err)
+		got = append(got, n)
+	}
+	is.Equal([]int32{50, 100, 116, 100, 212, 288}, got)
+}
@@ -0,0 +1,360 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+var (
+	tokenForm = [4]byte{'F', 'O', 'R', 'M'}
+	tokenAIFF = [4]byte{'A', 'I', 'F', 'F'}
+	tokenAIFC = [4]byte{'A', 'I', 'F', 'C'}
+	tokenComm = [4]byte{'C', 'O', 'M', 'M'}
+	tokenSsnd = [4]byte{'S', 'S', 'N', 'D'}
+	tokenFver = [4]byte{'F', 'V', 'E', 'R'}
+
+	// AIFF-C compression types
+	compressionNone = [4]byte{'N', 'O', 'N', 'E'}
+	compressionTwos = [4]byte{'t', 'w', 'o', 's'}
+	compressionSowt = [4]byte{'s', 'o', 'w', 't'}
+	compressionFl32 = [4]byte{'f', 'l', '3', '2'}
+	compressionFL32 = [4]byte{'F', 'L', '3', '2'}
+	compressionFl64 = [4]byte{'f', 'l', '6', '4'}
+	compressionFL64 = [4]byte{'F', 'L', '6', '4'}
+)
+
+// aifcVersion1 is the timestamp in the FVER chunk of AIFF-C version 1
+const aifcVersion1 = 0xA2805140
+
+// 18 byte COMM chunk. AIFF-C appends the compression type and a pascal string naming it.
+type aiffChunkComm struct {
+	NumChannels     int16
+	NumSampleFrames uint32
+	SampleSize      int16
+	SampleRate      [10]byte // 80 bit IEEE 754 extended precision
+}
+
+// extendedToFloat64 converts an 80 bit IEEE 754 extended precision number
+func extendedToFloat64(b [10]byte) float64 {
+	exp := int(binary.BigEndian.Uint16(b[0:2]))
+	mant := binary.BigEndian.Uint64(b[2:10])
+
+	sign := exp & 0x8000
+	exp &= 0x7FFF
+	if exp == 0 && mant == 0 {
+		return 0
+	}
+
+	f := math.Ldexp(float64(mant), exp-16383-63)
+	if sign != 0 {
+		f = -f
+	}
+	return f
+}
+
+// uint32ToExtended converts v to an 80 bit IEEE 754 extended precision number
+func uint32ToExtended(v uint32) (b [10]byte) {
+	if v == 0 {
+		return
+	}
+
+	exp := 16383 + 63
+	mant := uint64(v)
+	for mant&(1<<63) == 0 {
+		mant <<= 1
+		exp--
+	}
+
+	binary.BigEndian.PutUint16(b[0:2], uint16(exp))
+	binary.BigEndian.PutUint64(b[2:10], mant)
+	return
+}
+
+// pascalString returns s prefixed with its length and padded to an even length
+func pascalString(s string) []byte {
+	b := append([]byte{byte(len(s))}, s...)
+	if len(b)%2 == 1 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+// NewAIFFReader returns a new reader for AIFF and AIFF-C streams.
+// It supplies the same File description and sample API as the WAV Reader.
+// Supported AIFF-C compression types are NONE, twos, sowt, fl32 and fl64.
+func NewAIFFReader(rd io.ReadSeeker, size int64) (wav *Reader, err error) {
+	wav = new(Reader)
+	wav.input = rd
+	wav.size = size
+	wav.order = binary.BigEndian
+	wav.nativeOrder = binary.BigEndian
+
+	err = wav.parseAIFFHeaders()
+	if err != nil {
+		return nil, err
+	}
+
+	return wav, nil
+}
+
+func (wav *Reader) parseAIFFHeaders() (err error) {
+	wav.header = &riffHeader{}
+	var (
+		chunk       [4]byte
+		chunkSize   uint32
+		comm        *aiffChunkComm
+		compression = compressionNone
+		ssndFound   bool
+	)
+
+	if err = binary.Read(wav.input, binary.BigEndian, wav.header); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	if wav.header.Ftype != tokenForm {
+		return ErrNotAIFF
+	}
+
+	if int64(wav.header.ChunkSize)+8 != wav.size {
+		return ErrIncorrectChunkSize{int64(wav.header.ChunkSize) + 8, wav.size}
+	}
+
+	aifc := wav.header.ChunkFormat == tokenAIFC
+	if wav.header.ChunkFormat != tokenAIFF && !aifc {
+		return ErrNotAIFF
+	}
+
+	// the chunks can come in any order, so we have to look at all of them
+	pos := int64(12)
+	for pos+8 <= wav.size {
+		if err = binary.Read(wav.input, binary.BigEndian, &chunk); err != nil {
+			return err
+		}
+
+		if err = binary.Read(wav.input, binary.BigEndian, &chunkSize); err != nil {
+			return err
+		}
+		pos += 8
+
+		next := pos + int64(chunkSize) + int64(chunkSize%2)
+
+		switch chunk {
+		case tokenComm:
+			if chunkSize < 18 || (aifc && chunkSize < 22) {
+				return ErrBrokenChunkComm
+			}
+
+			comm = new(aiffChunkComm)
+			if err = binary.Read(wav.input, binary.BigEndian, comm); err != nil {
+				return err
+			}
+
+			if aifc {
+				if err = binary.Read(wav.input, binary.BigEndian, &compression); err != nil {
+					return err
+				}
+			}
+		case tokenSsnd:
+			if chunkSize < 8 {
+				return ErrBrokenChunkSsnd
+			}
+
+			var ssnd struct {
+				Offset, BlockSize uint32
+			}
+			if err = binary.Read(wav.input, binary.BigEndian, &ssnd); err != nil {
+				return err
+			}
+
+			if ssnd.Offset > chunkSize-8 {
+				return ErrBrokenChunkSsnd
+			}
+
+			ssndFound = true
+			wav.firstSamplePos = uint32(pos + 8 + int64(ssnd.Offset))
+			wav.dataBlocSize = uint64(chunkSize - 8 - ssnd.Offset)
+		default:
+			wav.extraChunk = true
+		}
+		wav.chunks = append(wav.chunks, Chunk{ID: string(chunk[:]), Offset: pos, Size: int64(chunkSize)})
+
+		if _, err = wav.input.Seek(next, os.SEEK_SET); err != nil {
+			return err
+		}
+		pos = next
+	}
+
+	if comm == nil {
+		return ErrBrokenChunkComm
+	}
+
+	if comm.NumChannels <= 0 || comm.SampleSize <= 0 || comm.SampleSize > 64 {
+		return ErrBrokenChunkComm
+	}
+
+	if !ssndFound {
+		if comm.NumSampleFrames != 0 {
+			return io.ErrUnexpectedEOF
+		}
+		wav.firstSamplePos = uint32(wav.size)
+	}
+
+	wav.chunkFmt = &riffChunkFmt{
+		AudioFormat: AudioFormatPCM,
+		NumChannels: uint16(comm.NumChannels),
+		SampleRate:  uint32(extendedToFloat64(comm.SampleRate) + 0.5),
+	}
+
+	switch compression {
+	case compressionNone, compressionTwos:
+	case compressionSowt:
+		wav.order = binary.LittleEndian
+	case compressionFl32, compressionFL32:
+		wav.chunkFmt.AudioFormat = AudioFormatIEEEFloat
+		comm.SampleSize = 32
+	case compressionFl64, compressionFL64:
+		wav.chunkFmt.AudioFormat = AudioFormatIEEEFloat
+		comm.SampleSize = 64
+	default:
+		return ErrFormatNotSupported
+	}
+
+	// samples are stored left justified in whole bytes
+	containerBytes := (uint16(comm.SampleSize) + 7) / 8
+	wav.chunkFmt.BitsPerSample = containerBytes * 8
+	if uint16(comm.SampleSize) != wav.chunkFmt.BitsPerSample {
+		wav.validBits = uint16(comm.SampleSize)
+	}
+	wav.chunkFmt.BytesPerBloc = containerBytes * wav.chunkFmt.NumChannels
+	wav.chunkFmt.BytesPerSec = uint32(wav.chunkFmt.BytesPerBloc) * wav.chunkFmt.SampleRate
+
+	if frames := uint64(comm.NumSampleFrames) * uint64(wav.chunkFmt.BytesPerBloc); frames < wav.dataBlocSize {
+		wav.dataBlocSize = frames
+	}
+
+	if _, err = wav.input.Seek(int64(wav.firstSamplePos), os.SEEK_SET); err != nil {
+		return err
+	}
+
+	return wav.setupSamples()
+}
+
+// NewAIFFWriter creates a new Writer for AIFF files and writes the header to it.
+// IEEE float and little endian PCM samples, the latter selected by setting ByteOrder
+// to binary.LittleEndian, are written as AIFF-C with the fl32, fl64 or sowt compression type.
+func (file File) NewAIFFWriter(out output) (wr *Writer, err error) {
+	if file.Channels == 0 {
+		err = fmt.Errorf("need at least one channel")
+		return
+	}
+
+	if file.AudioFormat == 0 {
+		file.AudioFormat = AudioFormatPCM
+	}
+
+	if file.ValidBits > file.SignificantBits {
+		err = fmt.Errorf("ValidBits %d exceed SignificantBits %d", file.ValidBits, file.SignificantBits)
+		return
+	}
+
+	littleEndian := file.ByteOrder == binary.LittleEndian
+
+	var compression [4]byte
+	var compressionName string
+	switch {
+	case file.AudioFormat == AudioFormatPCM && file.SignificantBits%8 == 0 && file.SignificantBits > 0 && file.SignificantBits <= 32:
+		if littleEndian {
+			compression = compressionSowt
+		}
+	case file.AudioFormat == AudioFormatIEEEFloat && file.SignificantBits == 32 && !littleEndian:
+		compression, compressionName = compressionFl32, "32-bit floating point"
+	case file.AudioFormat == AudioFormatIEEEFloat && file.SignificantBits == 64 && !littleEndian:
+		compression, compressionName = compressionFl64, "64-bit floating point"
+	default:
+		err = ErrFormatNotSupported
+		return
+	}
+	aifc := compression != [4]byte{}
+
+	if littleEndian {
+		wr = newWriter(out, file, binary.LittleEndian)
+	} else {
+		wr = newWriter(out, file, binary.BigEndian)
+	}
+	wr.finish = wr.finishAIFF
+	wr.unsigned = false
+
+	sampleSize := file.SignificantBits
+	if file.ValidBits != 0 {
+		sampleSize = file.ValidBits
+	}
+
+	// sizes are zero for now and get corrected on Close
+	var hdr bytes.Buffer
+	header := riffHeader{
+		Ftype:       tokenForm,
+		ChunkFormat: tokenAIFF,
+	}
+	if aifc {
+		header.ChunkFormat = tokenAIFC
+	}
+	binary.Write(&hdr, binary.BigEndian, header)
+
+	if aifc {
+		hdr.Write(tokenFver[:])
+		binary.Write(&hdr, binary.BigEndian, uint32(4))
+		binary.Write(&hdr, binary.BigEndian, uint32(aifcVersion1))
+	}
+
+	comm := aiffChunkComm{
+		NumChannels: int16(file.Channels),
+		SampleSize:  int16(sampleSize),
+		SampleRate:  uint32ToExtended(file.SampleRate),
+	}
+	commSize := 18
+	name := pascalString(compressionName)
+	if aifc {
+		commSize += 4 + len(name)
+	}
+
+	hdr.Write(tokenComm[:])
+	binary.Write(&hdr, binary.BigEndian, uint32(commSize))
+	wr.framesPos = int64(hdr.Len()) + 2 // NumSampleFrames
+	binary.Write(&hdr, binary.BigEndian, comm)
+	if aifc {
+		hdr.Write(compression[:])
+		hdr.Write(name)
+	}
+
+	hdr.Write(tokenSsnd[:])
+	binary.Write(&hdr, binary.BigEndian, uint32(0))
+	binary.Write(&hdr, binary.BigEndian, [2]uint32{}) // Offset, BlockSize
+
+	return wr, wr.writeHeader(&hdr)
+}
+
+// finishAIFF writes the FORM and SSND sizes and the number of sample frames
+func (w *Writer) finishAIFF() error {
+	formSize := w.headerSize - 8 + w.bytesWritten + w.padding()
+	if formSize > math.MaxUint32 {
+		return ErrInputToLarge
+	}
+
+	if err := w.patch(4, binary.BigEndian, uint32(formSize)); err != nil {
+		return err
+	}
+
+	frameSize := int64(w.options.containerBytes()) * int64(w.options.Channels)
+	if err := w.patch(w.framesPos, binary.BigEndian, uint32(w.bytesWritten/frameSize)); err != nil {
+		return err
+	}
+
+	return w.patch(w.headerSize-12, binary.BigEndian, uint32(8+w.bytesWritten))
+}
@@ -0,0 +1,190 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/cheekybits/is"
+)
+
+func TestExtended(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	rate44k := [10]byte{0x40, 0x0e, 0xac, 0x44, 0, 0, 0, 0, 0, 0}
+	is.Equal(rate44k, uint32ToExtended(44100))
+	is.Equal(44100.0, extendedToFloat64(rate44k))
+	for _, rate := range []uint32{1, 8000, 22050, 48000, 96000, 192000, 0xFFFFFFFF} {
+		is.Equal(float64(rate), extendedToFloat64(uint32ToExtended(rate)))
+	}
+	is.Equal(0.0, extendedToFloat64(uint32ToExtended(0)))
+}
+
+func TestParseAIFFHeaders(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	// SSND before COMM, with an offset and a trailing chunk of odd length
+	var body bytes.Buffer
+	body.Write(tokenAIFF[:])
+	body.WriteString("SSND")
+	binary.Write(&body, binary.BigEndian, uint32(8+2+4))
+	binary.Write(&body, binary.BigEndian, uint32(2)) // Offset
+	binary.Write(&body, binary.BigEndian, uint32(0)) // BlockSize
+	body.Write([]byte{0xaa, 0xbb})                   // skipped by offset
+	body.Write([]byte{0x01, 0x02, 0xff, 0xfe})
+	body.WriteString("NAME")
+	binary.Write(&body, binary.BigEndian, uint32(3))
+	body.Write([]byte{'a', 'b', 'c', 0})
+	body.WriteString("COMM")
+	binary.Write(&body, binary.BigEndian, uint32(18))
+	binary.Write(&body, binary.BigEndian, aiffChunkComm{
+		NumChannels:     1,
+		NumSampleFrames: 2,
+		SampleSize:      16,
+		SampleRate:      uint32ToExtended(44100),
+	})
+
+	var b bytes.Buffer
+	b.Write(tokenForm[:])
+	binary.Write(&b, binary.BigEndian, uint32(body.Len()))
+	b.Write(body.Bytes())
+
+	rd, err := NewAIFFReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	is.NoErr(err)
+	is.Equal(File{
+		SampleRate:      44100,
+		Channels:        1,
+		SignificantBits: 16,
+		AudioFormat:     AudioFormatPCM,
+		NumberOfSamples: 2,
+		SoundSize:       4,
+		BytesPerSecond:  88200,
+		Duration:        45351 * time.Nanosecond,
+	}, rd.GetFile())
+
+	raw, err := rd.ReadRawSample()
+	is.NoErr(err)
+	is.Equal([]byte{0x01, 0x02}, raw)
+	raw, err = rd.ReadRawSample()
+	is.NoErr(err)
+	is.Equal([]byte{0xff, 0xfe}, raw)
+	_, err = rd.ReadRawSample()
+	is.Equal(io.EOF, err)
+}
+
+func TestParseAIFFHeaders_notAIFF(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	_, err := NewAIFFReader(bytes.NewReader(wavWithOneSample), int64(len(wavWithOneSample)))
+	is.Equal(ErrNotAIFF, err)
+}
+
+func TestParseAIFFHeaders_noComm(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	b := []byte("FORM\x00\x00\x00\x04AIFF")
+	_, err := NewAIFFReader(bytes.NewReader(b), int64(len(b)))
+	is.Equal(ErrBrokenChunkComm, err)
+}
+
+func writeAIFF(t *testing.T, meta File, write func(*Writer)) *Reader {
+	is := is.New(t)
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+	defer os.Remove(f.Name())
+
+	wr, err := meta.NewAIFFWriter(f)
+	is.NoErr(err)
+	write(wr)
+	is.NoErr(wr.Close())
+
+	b, err := ioutil.ReadFile(f.Name())
+	is.NoErr(err)
+
+	rd, err := NewAIFFReader(bytes.NewReader(b), int64(len(b)))
+	is.NoErr(err)
+	return rd
+}
+
+func TestWriteReadAIFF(t *testing.T) {
+	is := is.New(t)
+	meta := File{
+		Channels:        2,
+		SampleRate:      48000,
+		SignificantBits: 32,
+	}
+	rd := writeAIFF(t, meta, func(wr *Writer) {
+		for _, s := range []int32{1, -1, 1 << 20, -(1 << 20)} {
+			is.NoErr(wr.WriteInt32(s))
+		}
+	})
+	got := rd.GetFile()
+	is.Equal(uint32(48000), got.SampleRate)
+	is.Equal(uint16(2), got.Channels)
+	is.Nil(got.ByteOrder)
+	is.Equal(uint64(4), got.NumberOfSamples)
+	for _, s := range []int32{1, -1, 1 << 20, -(1 << 20)} {
+		n, err := rd.ReadSample()
+		is.NoErr(err)
+		is.Equal(s, n)
+	}
+}
+
+func TestWriteReadAIFC_sowt(t *testing.T) {
+	is := is.New(t)
+	meta := File{
+		Channels:        1,
+		SampleRate:      44100,
+		SignificantBits: 32,
+		ByteOrder:       binary.LittleEndian,
+	}
+	rd := writeAIFF(t, meta, func(wr *Writer) {
+		is.NoErr(wr.WriteInt32(0x01020304))
+	})
+	is.Equal(binary.LittleEndian, rd.GetFile().ByteOrder)
+	raw, err := rd.ReadRawSample()
+	is.NoErr(err)
+	is.Equal([]byte{4, 3, 2, 1}, raw)
+}
+
+func TestWriteReadAIFC_float(t *testing.T) {
+	for _, bits := range []uint16{32, 64} {
+		is := is.New(t)
+		meta := File{
+			Channels:        1,
+			SampleRate:      96000,
+			SignificantBits: bits,
+			AudioFormat:     AudioFormatIEEEFloat,
+		}
+		rd := writeAIFF(t, meta, func(wr *Writer) {
+			is.NoErr(wr.WriteFloat64(0.5))
+			is.NoErr(wr.WriteFloat64(-0.25))
+		})
+		is.Equal(AudioFormatIEEEFloat, rd.GetAudioFormat())
+		is.Equal(bits, rd.GetBitsPerSample())
+		for _, s := range []float64{0.5, -0.25} {
+			v, err := rd.ReadFloat64()
+			is.NoErr(err)
+			is.Equal(s, v)
+		}
+	}
+}
+
+func TestNewAIFFWriter_notSupported(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	meta := File{
+		Channels:        1,
+		SampleRate:      8000,
+		SignificantBits: 8,
+		AudioFormat:     AudioFormatALaw,
+	}
+	_, err := meta.NewAIFFWriter(nil)
+	is.Equal(ErrFormatNotSupported, err)
+}
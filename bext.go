@@ -0,0 +1,135 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+var tokenBext = [4]byte{'b', 'e', 'x', 't'}
+
+// Bext is the broadcast audio extension chunk of Broadcast Wave files (EBU Tech 3285).
+// Strings longer than their field in the chunk are cut off by the Writer.
+type Bext struct {
+	Description         string // 256 characters
+	Originator          string // 32 characters
+	OriginatorReference string // 32 characters
+	OriginationDate     string // yyyy-mm-dd
+	OriginationTime     string // hh:mm:ss
+
+	// TimeReference is the position of the first sample in samples since midnight
+	TimeReference uint64
+	Version       uint16
+	UMID          [64]byte
+
+	// Loudness in hundredths of LUFS, LU or dBTP, since version 2
+	LoudnessValue        int16
+	LoudnessRange        int16
+	MaxTruePeakLevel     int16
+	MaxMomentaryLoudness int16
+	MaxShortTermLoudness int16
+
+	CodingHistory string
+}
+
+// 602 bytes in front of the coding history
+type bextChunk struct {
+	Description          [256]byte
+	Originator           [32]byte
+	OriginatorReference  [32]byte
+	OriginationDate      [10]byte
+	OriginationTime      [8]byte
+	TimeReferenceLow     uint32
+	TimeReferenceHigh    uint32
+	Version              uint16
+	UMID                 [64]byte
+	LoudnessValue        int16
+	LoudnessRange        int16
+	MaxTruePeakLevel     int16
+	MaxMomentaryLoudness int16
+	MaxShortTermLoudness int16
+	Reserved             [180]byte
+}
+
+const bextSize = 602
+
+// GetBext returns the bext chunk of Broadcast Wave files, nil if the file has none
+func (wav *Reader) GetBext() *Bext {
+	return wav.bext
+}
+
+// parseChunkBext reads a bext chunk of chunkSize bytes, shorter and truncated chunks are ignored
+func (wav *Reader) parseChunkBext(chunkSize uint32) error {
+	pos, err := wav.input.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return err
+	}
+	if chunkSize < bextSize || int64(chunkSize) > wav.size-pos {
+		return nil
+	}
+
+	var c bextChunk
+	if err := binary.Read(wav.input, wav.order, &c); err != nil {
+		return err
+	}
+
+	history := make([]byte, chunkSize-bextSize)
+	if _, err := io.ReadFull(wav.input, history); err != nil {
+		return err
+	}
+
+	wav.bext = &Bext{
+		Description:          cString(c.Description[:]),
+		Originator:           cString(c.Originator[:]),
+		OriginatorReference:  cString(c.OriginatorReference[:]),
+		OriginationDate:      cString(c.OriginationDate[:]),
+		OriginationTime:      cString(c.OriginationTime[:]),
+		TimeReference:        uint64(c.TimeReferenceHigh)<<32 | uint64(c.TimeReferenceLow),
+		Version:              c.Version,
+		UMID:                 c.UMID,
+		LoudnessValue:        c.LoudnessValue,
+		LoudnessRange:        c.LoudnessRange,
+		MaxTruePeakLevel:     c.MaxTruePeakLevel,
+		MaxMomentaryLoudness: c.MaxMomentaryLoudness,
+		MaxShortTermLoudness: c.MaxShortTermLoudness,
+		CodingHistory:        cString(history),
+	}
+	return nil
+}
+
+// chunk returns the bext chunk
+func (bext *Bext) chunk(order binary.ByteOrder) []byte {
+	c := bextChunk{
+		TimeReferenceLow:     uint32(bext.TimeReference),
+		TimeReferenceHigh:    uint32(bext.TimeReference >> 32),
+		Version:              bext.Version,
+		UMID:                 bext.UMID,
+		LoudnessValue:        bext.LoudnessValue,
+		LoudnessRange:        bext.LoudnessRange,
+		MaxTruePeakLevel:     bext.MaxTruePeakLevel,
+		MaxMomentaryLoudness: bext.MaxMomentaryLoudness,
+		MaxShortTermLoudness: bext.MaxShortTermLoudness,
+	}
+	copy(c.Description[:], bext.Description)
+	copy(c.Originator[:], bext.Originator)
+	copy(c.OriginatorReference[:], bext.OriginatorReference)
+	copy(c.OriginationDate[:], bext.OriginationDate)
+	copy(c.OriginationTime[:], bext.OriginationTime)
+
+	var body bytes.Buffer
+	binary.Write(&body, order, c)
+	body.WriteString(bext.CodingHistory)
+
+	var b bytes.Buffer
+	writeChunk(&b, order, tokenBext, body.Bytes())
+	return b.Bytes()
+}
+
+// cString returns the text of a zero padded field
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
@@ -0,0 +1,98 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestWriteReadBext(t *testing.T) {
+	is := is.New(t)
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+	defer os.Remove(f.Name())
+
+	bext := &Bext{
+		Description:          "Interview, take 3",
+		Originator:           "Studio A",
+		OriginatorReference:  "USID0123456789",
+		OriginationDate:      "2016-03-01",
+		OriginationTime:      "12:30:00",
+		TimeReference:        1<<32 + 48000*3600*12,
+		Version:              2,
+		LoudnessValue:        -2300,
+		LoudnessRange:        540,
+		MaxTruePeakLevel:     -100,
+		MaxMomentaryLoudness: -1800,
+		MaxShortTermLoudness: -2000,
+		CodingHistory:        "A=PCM,F=48000,W=24,M=stereo,T=original\r\n",
+	}
+	bext.UMID[0] = 0x06
+
+	meta := File{
+		Channels:        2,
+		SampleRate:      48000,
+		SignificantBits: 24,
+		Bext:            bext,
+	}
+	wr, err := meta.NewWriter(f)
+	is.NoErr(err)
+	is.NoErr(wr.WriteInt32(-1))
+	is.NoErr(wr.WriteInt32(1))
+	is.NoErr(wr.Close())
+
+	buf, err := ioutil.ReadFile(f.Name())
+	is.NoErr(err)
+
+	// the chunk has the layout of EBU Tech 3285
+	i := bytes.Index(buf, tokenBext[:])
+	is.True(i > 0)
+	is.Equal(uint32(bextSize+len(bext.CodingHistory)), binary.LittleEndian.Uint32(buf[i+4:]))
+	is.Equal("Studio A", string(buf[i+8+256:i+8+256+8]))
+	is.Equal(uint32(48000*3600*12), binary.LittleEndian.Uint32(buf[i+8+338:]))
+	is.Equal(uint32(1), binary.LittleEndian.Uint32(buf[i+8+342:]))
+	is.True(i < bytes.Index(buf, tokenData[:]))
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(bext, rd.GetBext())
+	is.Equal(bext, rd.GetFile().Bext)
+
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(-1), s)
+}
+
+func TestParseBext_short(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var c bytes.Buffer
+	writeChunk(&c, binary.LittleEndian, tokenBext, make([]byte, 100))
+	buf := infoWave(c.Bytes())
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Nil(rd.GetBext())
+}
+
+func TestParseBext_truncated(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	// the trailing chunk claims far more bytes than the file has
+	c := append([]byte{'b', 'e', 'x', 't', 0, 0, 0, 0xf0}, make([]byte, bextSize+100)...)
+	buf := infoWave(c)
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Nil(rd.GetBext())
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(1), s)
+}
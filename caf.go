@@ -0,0 +1,234 @@
+package wav
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+)
+
+var (
+	tokenCaff = [4]byte{'c', 'a', 'f', 'f'}
+	tokenDesc = [4]byte{'d', 'e', 's', 'c'}
+	tokenPakt = [4]byte{'p', 'a', 'k', 't'}
+
+	// CAF audio format IDs
+	cafFormatLPCM = [4]byte{'l', 'p', 'c', 'm'}
+	cafFormatALaw = [4]byte{'a', 'l', 'a', 'w'}
+	cafFormatULaw = [4]byte{'u', 'l', 'a', 'w'}
+)
+
+// mFormatFlags of linear PCM, the last three are those of CoreAudio which some writers keep
+const (
+	cafLinearPCMFormatFlagIsFloat         = 1 << 0
+	cafLinearPCMFormatFlagIsLittleEndian  = 1 << 1
+	cafLinearPCMFormatFlagIsSignedInteger = 1 << 2
+	cafLinearPCMFormatFlagIsPacked        = 1 << 3
+	cafLinearPCMFormatFlagIsAlignedHigh   = 1 << 4
+)
+
+// 8 byte CAF file header
+type cafHeader struct {
+	FileType    [4]byte
+	FileVersion uint16
+	FileFlags   uint16
+}
+
+// 12 byte CAF chunk header, a size of -1 marks a data chunk reaching to the end of the file
+type cafChunkHeader struct {
+	ChunkType [4]byte
+	ChunkSize int64
+}
+
+// 32 byte desc chunk
+type cafChunkDesc struct {
+	SampleRate       float64
+	FormatID         [4]byte
+	FormatFlags      uint32
+	BytesPerPacket   uint32
+	FramesPerPacket  uint32
+	ChannelsPerFrame uint32
+	BitsPerChannel   uint32
+}
+
+// first 24 bytes of the pakt chunk, the packet table follows
+type cafChunkPakt struct {
+	NumberPackets     int64
+	NumberValidFrames int64
+	PrimingFrames     int32
+	RemainderFrames   int32
+}
+
+// NewCAFReader returns a new reader for Apple Core Audio Format streams.
+// It supplies the same File description and sample API as the WAV Reader.
+// Linear PCM, integer and float in both byte orders, and A-law and μ-law are supported.
+func NewCAFReader(rd io.ReadSeeker, size int64) (wav *Reader, err error) {
+	wav = new(Reader)
+	wav.input = rd
+	wav.size = size
+	wav.order = binary.BigEndian
+	wav.nativeOrder = binary.BigEndian
+
+	err = wav.parseCAFHeaders()
+	if err != nil {
+		return nil, err
+	}
+
+	return wav, nil
+}
+
+func (wav *Reader) parseCAFHeaders() (err error) {
+	var (
+		header    cafHeader
+		desc      *cafChunkDesc
+		pakt      *cafChunkPakt
+		dataFound bool
+	)
+
+	if err = binary.Read(wav.input, binary.BigEndian, &header); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	if header.FileType != tokenCaff || header.FileVersion != 1 {
+		return ErrNotCAF
+	}
+
+	pos := int64(8)
+	for pos+12 <= wav.size {
+		var chunk cafChunkHeader
+		if err = binary.Read(wav.input, binary.BigEndian, &chunk); err != nil {
+			return err
+		}
+		pos += 12
+
+		if chunk.ChunkSize == -1 && chunk.ChunkType == tokenData {
+			chunk.ChunkSize = wav.size - pos
+		}
+		if chunk.ChunkSize < 0 || chunk.ChunkSize > wav.size-pos {
+			return ErrIncorrectChunkSize{chunk.ChunkSize, wav.size - pos}
+		}
+
+		switch chunk.ChunkType {
+		case tokenDesc:
+			if chunk.ChunkSize < 32 {
+				return ErrBrokenChunkDesc
+			}
+			desc = new(cafChunkDesc)
+			if err = binary.Read(wav.input, binary.BigEndian, desc); err != nil {
+				return err
+			}
+		case tokenPakt:
+			if chunk.ChunkSize < 24 {
+				return ErrBrokenChunkPakt
+			}
+			pakt = new(cafChunkPakt)
+			if err = binary.Read(wav.input, binary.BigEndian, pakt); err != nil {
+				return err
+			}
+		case tokenData:
+			if chunk.ChunkSize < 4 {
+				return ErrIncorrectChunkSize{chunk.ChunkSize, 4}
+			}
+			// the data starts with an edit count
+			dataFound = true
+			wav.firstSamplePos = uint32(pos + 4)
+			wav.dataBlocSize = uint64(chunk.ChunkSize - 4)
+		default:
+			wav.extraChunk = true
+		}
+
+		pos += chunk.ChunkSize
+		if _, err = wav.input.Seek(pos, os.SEEK_SET); err != nil {
+			return err
+		}
+	}
+
+	if desc == nil {
+		return ErrBrokenChunkDesc
+	}
+
+	if !dataFound {
+		return io.ErrUnexpectedEOF
+	}
+
+	if desc.ChannelsPerFrame == 0 || desc.ChannelsPerFrame > math.MaxUint16 ||
+		desc.FramesPerPacket != 1 || desc.BytesPerPacket == 0 || desc.BytesPerPacket%desc.ChannelsPerFrame != 0 ||
+		desc.SampleRate <= 0 || desc.SampleRate > math.MaxUint32 {
+		return ErrBrokenChunkDesc
+	}
+
+	containerBytes := desc.BytesPerPacket / desc.ChannelsPerFrame
+	if containerBytes > 8 || desc.BitsPerChannel > 8*containerBytes {
+		return ErrBrokenChunkDesc
+	}
+
+	wav.chunkFmt = &riffChunkFmt{
+		AudioFormat:   AudioFormatPCM,
+		NumChannels:   uint16(desc.ChannelsPerFrame),
+		SampleRate:    uint32(desc.SampleRate + 0.5),
+		BytesPerBloc:  uint16(desc.BytesPerPacket),
+		BitsPerSample: uint16(8 * containerBytes),
+	}
+	wav.chunkFmt.BytesPerSec = uint32(wav.chunkFmt.BytesPerBloc) * wav.chunkFmt.SampleRate
+
+	switch desc.FormatID {
+	case cafFormatLPCM:
+		if desc.FormatFlags&cafLinearPCMFormatFlagIsLittleEndian != 0 {
+			wav.order = binary.LittleEndian
+		}
+		if desc.FormatFlags&cafLinearPCMFormatFlagIsFloat != 0 {
+			wav.chunkFmt.AudioFormat = AudioFormatIEEEFloat
+			if desc.BitsPerChannel != 32 && desc.BitsPerChannel != 64 {
+				return ErrFormatNotSupported
+			}
+			break
+		}
+
+		// integers are signed, unless the CoreAudio flags are used without IsSignedInteger
+		coreAudio := uint32(cafLinearPCMFormatFlagIsSignedInteger) | cafLinearPCMFormatFlagIsPacked | cafLinearPCMFormatFlagIsAlignedHigh
+		if desc.FormatFlags&coreAudio != 0 && desc.FormatFlags&cafLinearPCMFormatFlagIsSignedInteger == 0 {
+			if containerBytes != 1 {
+				return ErrFormatNotSupported
+			}
+			wav.unsigned = true
+		}
+		// the valid bits have to be the high ones of the container
+		if desc.BitsPerChannel != 0 && desc.BitsPerChannel < 8*containerBytes &&
+			desc.FormatFlags&cafLinearPCMFormatFlagIsAlignedHigh == 0 {
+			return ErrFormatNotSupported
+		}
+	case cafFormatALaw:
+		wav.chunkFmt.AudioFormat = AudioFormatALaw
+	case cafFormatULaw:
+		wav.chunkFmt.AudioFormat = AudioFormatMULaw
+	default:
+		return ErrFormatNotSupported
+	}
+
+	if desc.BitsPerChannel != 0 && uint16(desc.BitsPerChannel) != wav.chunkFmt.BitsPerSample {
+		wav.validBits = uint16(desc.BitsPerChannel)
+	}
+
+	// the packet table knows about priming and remainder frames
+	if pakt != nil && pakt.NumberValidFrames >= 0 && pakt.PrimingFrames >= 0 {
+		priming := uint64(pakt.PrimingFrames) * uint64(desc.BytesPerPacket)
+		if priming > wav.dataBlocSize {
+			priming = wav.dataBlocSize
+		}
+		wav.firstSamplePos += uint32(priming)
+		wav.dataBlocSize -= priming
+
+		if valid := uint64(pakt.NumberValidFrames) * uint64(desc.BytesPerPacket); valid < wav.dataBlocSize {
+			wav.dataBlocSize = valid
+		}
+	}
+
+	if _, err = wav.input.Seek(int64(wav.firstSamplePos), os.SEEK_SET); err != nil {
+		return err
+	}
+
+	return wav.setupSamples()
+}
@@ -0,0 +1,241 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+// cafFile builds a CAF stream from desc and the samples in data
+func cafFile(desc cafChunkDesc, data []byte, dataSize int64, extra ...[]byte) []byte {
+	var b bytes.Buffer
+	binary.Write(&b, binary.BigEndian, cafHeader{tokenCaff, 1, 0})
+	binary.Write(&b, binary.BigEndian, cafChunkHeader{tokenDesc, 32})
+	binary.Write(&b, binary.BigEndian, desc)
+	for _, e := range extra {
+		b.Write(e)
+	}
+	binary.Write(&b, binary.BigEndian, cafChunkHeader{tokenData, dataSize})
+	binary.Write(&b, binary.BigEndian, uint32(0)) // edit count
+	b.Write(data)
+	return b.Bytes()
+}
+
+func TestCAF_int16LittleEndian(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	buf := cafFile(cafChunkDesc{
+		SampleRate:       48000,
+		FormatID:         cafFormatLPCM,
+		FormatFlags:      cafLinearPCMFormatFlagIsLittleEndian,
+		BytesPerPacket:   4,
+		FramesPerPacket:  1,
+		ChannelsPerFrame: 2,
+		BitsPerChannel:   16,
+	}, []byte{0x01, 0x00, 0xff, 0xff, 0x00, 0x80, 0xff, 0x7f}, 4+8)
+
+	rd, err := NewCAFReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+
+	file := rd.GetFile()
+	is.Equal(uint32(48000), file.SampleRate)
+	is.Equal(uint16(2), file.Channels)
+	is.Equal(uint16(16), file.SignificantBits)
+	is.Equal(AudioFormatPCM, file.AudioFormat)
+	is.Equal(uint64(4), file.NumberOfSamples)
+	is.Equal(binary.LittleEndian, file.ByteOrder)
+
+	for _, want := range []int32{1, -1, -32768, 32767} {
+		s, err := rd.ReadSample()
+		is.NoErr(err)
+		is.Equal(want, s)
+	}
+}
+
+func TestCAF_float32BigEndian(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var data bytes.Buffer
+	binary.Write(&data, binary.BigEndian, []float32{0.5, -0.25})
+
+	// data chunk size -1 reaches to the end of the file
+	buf := cafFile(cafChunkDesc{
+		SampleRate:       44100,
+		FormatID:         cafFormatLPCM,
+		FormatFlags:      cafLinearPCMFormatFlagIsFloat,
+		BytesPerPacket:   4,
+		FramesPerPacket:  1,
+		ChannelsPerFrame: 1,
+		BitsPerChannel:   32,
+	}, data.Bytes(), -1)
+
+	rd, err := NewCAFReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+
+	file := rd.GetFile()
+	is.Equal(AudioFormatIEEEFloat, file.AudioFormat)
+	is.Equal(uint64(2), file.NumberOfSamples)
+	is.Nil(file.ByteOrder)
+
+	f, err := rd.ReadFloat64()
+	is.NoErr(err)
+	is.Equal(0.5, f)
+	f, err = rd.ReadFloat64()
+	is.NoErr(err)
+	is.Equal(-0.25, f)
+}
+
+func TestCAF_pakt(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var pakt bytes.Buffer
+	binary.Write(&pakt, binary.BigEndian, cafChunkHeader{tokenPakt, 24})
+	binary.Write(&pakt, binary.BigEndian, cafChunkPakt{NumberValidFrames: 3})
+
+	buf := cafFile(cafChunkDesc{
+		SampleRate:       8000,
+		FormatID:         cafFormatULaw,
+		BytesPerPacket:   1,
+		FramesPerPacket:  1,
+		ChannelsPerFrame: 1,
+		BitsPerChannel:   8,
+	}, []byte{0xff, 0x7f, 0x80, 0x00}, 4+4, pakt.Bytes())
+
+	rd, err := NewCAFReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(AudioFormatMULaw, rd.GetAudioFormat())
+	is.Equal(uint64(3), rd.GetSampleCount())
+}
+
+func TestCAF_paktPriming(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var pakt bytes.Buffer
+	binary.Write(&pakt, binary.BigEndian, cafChunkHeader{tokenPakt, 24})
+	binary.Write(&pakt, binary.BigEndian, cafChunkPakt{NumberValidFrames: 2, PrimingFrames: 1, RemainderFrames: 1})
+
+	buf := cafFile(cafChunkDesc{
+		SampleRate:       8000,
+		FormatID:         cafFormatLPCM,
+		BytesPerPacket:   2,
+		FramesPerPacket:  1,
+		ChannelsPerFrame: 1,
+		BitsPerChannel:   16,
+	}, []byte{0x00, 0x09, 0x00, 0x01, 0xff, 0xfe, 0x00, 0x09}, 4+8, pakt.Bytes())
+
+	rd, err := NewCAFReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(uint64(2), rd.GetSampleCount())
+	for _, want := range []int32{1, -2} {
+		s, err := rd.ReadSample()
+		is.NoErr(err)
+		is.Equal(want, s)
+	}
+	_, err = rd.ReadSample()
+	is.Err(err)
+}
+
+func TestCAF_broken(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	_, err := NewCAFReader(bytes.NewReader(wavWithOneSample), int64(len(wavWithOneSample)))
+	is.Equal(ErrNotCAF, err)
+
+	buf := cafFile(cafChunkDesc{
+		SampleRate:       8000,
+		FormatID:         [4]byte{'a', 'a', 'c', ' '},
+		FramesPerPacket:  1024,
+		ChannelsPerFrame: 2,
+	}, nil, 4)
+	_, err = NewCAFReader(bytes.NewReader(buf), int64(len(buf)))
+	is.Equal(ErrBrokenChunkDesc, err)
+
+	buf = cafFile(cafChunkDesc{
+		SampleRate:       8000,
+		FormatID:         [4]byte{'a', 'a', 'c', ' '},
+		BytesPerPacket:   2,
+		FramesPerPacket:  1,
+		ChannelsPerFrame: 2,
+	}, nil, 4)
+	_, err = NewCAFReader(bytes.NewReader(buf), int64(len(buf)))
+	is.Equal(ErrFormatNotSupported, err)
+}
+
+func TestCAF_coreAudioFlags(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	read := func(desc cafChunkDesc, data []byte) (*Reader, error) {
+		desc.SampleRate = 8000
+		desc.FormatID = cafFormatLPCM
+		desc.FramesPerPacket = 1
+		desc.ChannelsPerFrame = 1
+		buf := cafFile(desc, data, int64(4+len(data)))
+		return NewCAFReader(bytes.NewReader(buf), int64(len(buf)))
+	}
+
+	// unsigned 8 bit
+	rd, err := read(cafChunkDesc{
+		FormatFlags:    cafLinearPCMFormatFlagIsPacked,
+		BytesPerPacket: 1,
+		BitsPerChannel: 8,
+	}, []byte{0x80, 0x81, 0x7f})
+	is.NoErr(err)
+	is.True(rd.GetFile().Unsigned)
+	for _, want := range []int32{0, 1, -1} {
+		s, err := rd.ReadSample()
+		is.NoErr(err)
+		is.Equal(want, s)
+	}
+
+	// signed 8 bit
+	rd, err = read(cafChunkDesc{
+		FormatFlags:    cafLinearPCMFormatFlagIsSignedInteger | cafLinearPCMFormatFlagIsPacked,
+		BytesPerPacket: 1,
+		BitsPerChannel: 8,
+	}, []byte{0x01, 0xff})
+	is.NoErr(err)
+	for _, want := range []int32{1, -1} {
+		s, err := rd.ReadSample()
+		is.NoErr(err)
+		is.Equal(want, s)
+	}
+
+	// 24 bits in the high bytes of 32
+	flags := uint32(cafLinearPCMFormatFlagIsLittleEndian | cafLinearPCMFormatFlagIsSignedInteger | cafLinearPCMFormatFlagIsAlignedHigh)
+	rd, err = read(cafChunkDesc{
+		FormatFlags:    flags,
+		BytesPerPacket: 4,
+		BitsPerChannel: 24,
+	}, []byte{0x00, 0x01, 0x00, 0x00, 0x00, 0xfe, 0xff, 0xff})
+	is.NoErr(err)
+	is.Equal(uint16(24), rd.GetValidBits())
+	for _, want := range []int32{1, -2} {
+		s, err := rd.ReadSample()
+		is.NoErr(err)
+		is.Equal(want, s)
+	}
+
+	// 24 bits in the low bytes of 32
+	_, err = read(cafChunkDesc{
+		FormatFlags:    flags &^ cafLinearPCMFormatFlagIsAlignedHigh,
+		BytesPerPacket: 4,
+		BitsPerChannel: 24,
+	}, make([]byte, 4))
+	is.Equal(ErrFormatNotSupported, err)
+
+	// unsigned 16 bit
+	_, err = read(cafChunkDesc{
+		FormatFlags:    cafLinearPCMFormatFlagIsPacked,
+		BytesPerPacket: 2,
+		BitsPerChannel: 16,
+	}, make([]byte, 2))
+	is.Equal(ErrFormatNotSupported, err)
+}
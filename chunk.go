@@ -0,0 +1,174 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"reflect"
+)
+
+// Chunk is a chunk of the file as found by the Reader.
+// Offset and Size are those of the body, without the eight byte header and the pad byte.
+type Chunk struct {
+	ID     string // four characters, like "fmt " or "LIST"
+	Offset int64
+	Size   int64
+}
+
+// RawChunk is a chunk as it is stored in the file, Data is in the byte order of the file.
+// Data is nil for the chunks of a Reader, Body reads them when they are needed.
+// The fmt, fact, data and JUNK chunks are written by the Writer itself and not kept.
+type RawChunk struct {
+	ID        string
+	Data      []byte
+	AfterData bool // the chunk follows the samples
+
+	src   *Reader // the body is read from src if Data is nil
+	chunk Chunk
+}
+
+// Body returns Data, or the body read from the file of the Reader the chunk comes from
+func (c RawChunk) Body() ([]byte, error) {
+	if c.Data != nil || c.src == nil {
+		return c.Data, nil
+	}
+	return c.src.ReadChunk(c.chunk)
+}
+
+// Chunks returns the chunks of RIFF, RIFX, RF64, BW64 and AIFF files in the order of the file,
+// including the ones following the samples. The sizes of RF64 files are taken from the ds64 chunk.
+func (wav *Reader) Chunks() []Chunk {
+	return wav.chunks
+}
+
+// ReadChunk returns the body of the chunk c.
+// The position of the sample readers is kept.
+func (wav *Reader) ReadChunk(c Chunk) ([]byte, error) {
+	if c.Offset < 0 || c.Size < 0 || c.Offset+c.Size > wav.size {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	pos, err := wav.input.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return nil, err
+	}
+
+	body := make([]byte, c.Size)
+	if _, err = wav.input.Seek(c.Offset, os.SEEK_SET); err != nil {
+		return nil, err
+	}
+	_, err = io.ReadFull(wav.input, body)
+
+	if _, serr := wav.input.Seek(pos, os.SEEK_SET); err == nil {
+		err = serr
+	}
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// metadata returns the metadata chunks in front of and after the samples.
+// The typed fields are written first, followed by the copied chunks in their original order.
+func (file File) metadata(order binary.ByteOrder) (front, back []byte, err error) {
+	chunks := make([]RawChunk, len(file.Chunks))
+	for i, c := range file.Chunks {
+		if c.Data, err = c.Body(); err != nil {
+			return nil, nil, err
+		}
+		chunks[i] = c
+	}
+	file.Chunks = chunks
+	dec := decodeChunks(file.Chunks, order)
+
+	// the copies are written as long as the fields are unchanged
+	same := map[[4]byte]bool{
+		tokenBext: reflect.DeepEqual(file.Bext, dec.bext),
+		tokenInfo: reflect.DeepEqual(file.Info, dec.info),
+		tokenIXML: reflect.DeepEqual(file.IXML, dec.ixml),
+		tokenCue:  reflect.DeepEqual(file.Cues, dec.cues),
+		tokenSmpl: reflect.DeepEqual(file.Sampler, dec.sampler),
+		tokenInst: reflect.DeepEqual(file.Instrument, dec.instrument),
+		tokenID3:  reflect.DeepEqual(file.ID3, dec.id3),
+		tokenAcid: reflect.DeepEqual(file.Acid, dec.acid),
+	}
+
+	var f, b bytes.Buffer
+	if file.Bext != nil && !same[tokenBext] {
+		f.Write(file.Bext.chunk(order))
+	}
+	if file.Info != nil && !same[tokenInfo] {
+		f.Write(file.Info.chunk(order))
+	}
+	if file.IXML != nil && !same[tokenIXML] {
+		f.Write(file.IXML.chunk(order, dec.ixml, dec.ixmlRaw))
+	}
+	if len(file.Cues) > 0 && !same[tokenCue] {
+		f.Write(cueChunks(file.Cues, order))
+	}
+	if file.Sampler != nil && !same[tokenSmpl] {
+		f.Write(file.Sampler.chunk(order))
+	}
+	if file.Instrument != nil && !same[tokenInst] {
+		f.Write(file.Instrument.chunk(order))
+	}
+	if file.ID3 != nil && !same[tokenID3] {
+		f.Write(file.ID3.chunk(order))
+	}
+	if file.Acid != nil && !same[tokenAcid] {
+		f.Write(file.Acid.chunk(order))
+	}
+
+	for _, c := range file.Chunks {
+		if field, ok := c.field(); ok && !same[field] {
+			continue
+		}
+		var id [4]byte
+		copy(id[:], c.ID+"    ")
+		if c.AfterData {
+			writeChunk(&b, order, id, c.Data)
+		} else {
+			writeChunk(&f, order, id, c.Data)
+		}
+	}
+	return f.Bytes(), b.Bytes(), nil
+}
+
+// field returns the token of the metadata the chunk is decoded to, if it is known
+func (c RawChunk) field() ([4]byte, bool) {
+	var id [4]byte
+	copy(id[:], c.ID)
+	switch id {
+	case tokenList:
+		if len(c.Data) < 4 {
+			return id, false
+		}
+		switch string(c.Data[:4]) {
+		case string(tokenInfo[:]):
+			return tokenInfo, true
+		case string(tokenAdtl[:]):
+			return tokenCue, true
+		}
+		return id, false
+	case tokenID3Upper:
+		return tokenID3, true
+	case tokenBext, tokenIXML, tokenCue, tokenSmpl, tokenInst, tokenID3, tokenAcid:
+		return id, true
+	}
+	return id, false
+}
+
+// decodeChunks parses the metadata of chunks like the Reader does
+func decodeChunks(chunks []RawChunk, order binary.ByteOrder) *Reader {
+	wav := &Reader{order: order}
+	for _, c := range chunks {
+		var id [4]byte
+		copy(id[:], c.ID)
+		wav.input = bytes.NewReader(c.Data)
+		wav.size = int64(len(c.Data))
+		wav.parseChunkMeta(id, uint32(len(c.Data)))
+	}
+	wav.mergeCues()
+	return wav
+}
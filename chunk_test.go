@@ -0,0 +1,183 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestChunks(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var c bytes.Buffer
+	writeChunk(&c, binary.LittleEndian, [4]byte{'D', 'I', 'S', 'P'}, []byte{1, 0, 0, 0, 'h', 'i', 0})
+	writeChunk(&c, binary.LittleEndian, [4]byte{'_', 'P', 'M', 'X'}, []byte("<x/>"))
+	buf := infoWave(c.Bytes())
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal([]Chunk{
+		{ID: "fmt ", Offset: 20, Size: 16},
+		{ID: "data", Offset: 44, Size: 2},
+		{ID: "DISP", Offset: 54, Size: 7},
+		{ID: "_PMX", Offset: 70, Size: 4},
+	}, rd.Chunks())
+
+	// reading a chunk doesn't move the samples
+	body, err := rd.ReadChunk(rd.Chunks()[2])
+	is.NoErr(err)
+	is.Equal([]byte{1, 0, 0, 0, 'h', 'i', 0}, body)
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(1), s)
+
+	body, err = rd.ReadChunk(rd.Chunks()[3])
+	is.NoErr(err)
+	is.Equal([]byte("<x/>"), body)
+
+	_, err = rd.ReadChunk(Chunk{ID: "junk", Offset: 70, Size: 100})
+	is.Err(err)
+}
+
+// countReader counts the bytes read from it
+type countReader struct {
+	*bytes.Reader
+	n int
+}
+
+func (r *countReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	r.n += n
+	return n, err
+}
+
+func TestChunks_lazy(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	buf := infoWave(append([]byte("LGWV\x00\x00\x01\x00"), make([]byte, 0x10000)...))
+	in := &countReader{Reader: bytes.NewReader(buf)}
+	rd, err := NewReader(in, int64(len(buf)))
+	is.NoErr(err)
+	is.True(in.n < 1024)
+
+	// the body is read when it is needed
+	raw := rd.GetFile().Chunks
+	is.Equal(1, len(raw))
+	is.Nil(raw[0].Data)
+	body, err := raw[0].Body()
+	is.NoErr(err)
+	is.Equal(0x10000, len(body))
+}
+
+func TestChunks_AIFF(t *testing.T) {
+	is := is.New(t)
+	rd := writeAIFF(t, File{Channels: 1, SampleRate: 8000, SignificantBits: 16}, func(wr *Writer) {
+		is.NoErr(wr.WriteInt32(-2))
+	})
+
+	chunks := rd.Chunks()
+	is.Equal(2, len(chunks))
+	is.Equal("COMM", chunks[0].ID)
+	is.Equal("SSND", chunks[1].ID)
+	is.Equal(int64(10), chunks[1].Size)
+
+	body, err := rd.ReadChunk(chunks[1])
+	is.NoErr(err)
+	is.Equal([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0xff, 0xfe}, body)
+}
+
+func TestWriteRead_copyChunks(t *testing.T) {
+	is := is.New(t)
+
+	// acid with values in the unknown fields, which only survive as a copy
+	acid := []byte{
+		1, 0, 0, 0, 60, 0, 0x12, 0x34, 1, 2, 3, 4,
+		4, 0, 0, 0, 4, 0, 4, 0, 0, 0, 0xf0, 0x42,
+	}
+	var list bytes.Buffer
+	list.Write([]byte("exif"))
+	writeChunk(&list, binary.LittleEndian, [4]byte{'e', 'v', 'e', 'r'}, []byte("0220"))
+
+	var body bytes.Buffer
+	body.Write(wave)
+	body.Write(fmt20)
+	body.Write(testRiffChunkFmt[:20])
+	writeChunk(&body, binary.LittleEndian, [4]byte{'D', 'I', 'S', 'P'}, []byte{1, 0, 0, 0, 'h', 'i', 0})
+	writeChunk(&body, binary.LittleEndian, tokenList, list.Bytes())
+	writeChunk(&body, binary.LittleEndian, tokenAcid, acid)
+	writeChunk(&body, binary.LittleEndian, tokenInfo, []byte("not a LIST"))
+	writeChunk(&body, binary.LittleEndian, tokenData, []byte{0x01, 0x00, 0xff, 0xff})
+	writeChunk(&body, binary.LittleEndian, [4]byte{'_', 'P', 'M', 'X'}, []byte("<x/>!"))
+	var orig bytes.Buffer
+	orig.Write(riff)
+	binary.Write(&orig, binary.LittleEndian, uint32(body.Len()))
+	orig.Write(body.Bytes())
+
+	rewrite := func(buf []byte, change func(*File)) *Reader {
+		rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+		is.NoErr(err)
+		meta := rd.GetFile()
+		change(&meta)
+
+		f, err := ioutil.TempFile("", "wavPkgtest")
+		is.NoErr(err)
+		defer os.Remove(f.Name())
+		wr, err := meta.NewWriter(f)
+		is.NoErr(err)
+		for i := uint64(0); i < rd.GetSampleCount(); i++ {
+			s, err := rd.ReadSample()
+			is.NoErr(err)
+			is.NoErr(wr.WriteInt32(s))
+		}
+		is.NoErr(wr.Close())
+
+		out, err := ioutil.ReadFile(f.Name())
+		is.NoErr(err)
+		rd, err = NewReader(bytes.NewReader(out), int64(len(out)))
+		is.NoErr(err)
+		return rd
+	}
+	ids := func(rd *Reader) (ids []string) {
+		for _, c := range rd.Chunks() {
+			ids = append(ids, c.ID)
+		}
+		return ids
+	}
+
+	rd := rewrite(orig.Bytes(), func(*File) {})
+	is.Equal([]string{"JUNK", "fmt ", "DISP", "LIST", "acid", "INFO", "data", "_PMX"}, ids(rd))
+	for i, want := range [][]byte{{1, 0, 0, 0, 'h', 'i', 0}, list.Bytes(), acid, []byte("not a LIST")} {
+		got, err := rd.ReadChunk(rd.Chunks()[i+2])
+		is.NoErr(err)
+		is.Equal(want, got)
+	}
+	got, err := rd.ReadChunk(rd.Chunks()[7])
+	is.NoErr(err)
+	is.Equal([]byte("<x/>!"), got)
+	is.Equal(true, rd.GetFile().Chunks[4].AfterData)
+
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(1), s)
+	s, err = rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(-1), s)
+
+	// a changed field replaces the copy
+	rd = rewrite(orig.Bytes(), func(f *File) {
+		f.Acid.Tempo = 90
+		f.Info = &Info{Title: "new"}
+	})
+	is.Equal([]string{"JUNK", "fmt ", "LIST", "acid", "DISP", "LIST", "INFO", "data", "_PMX"}, ids(rd))
+	is.Equal(float32(90), rd.GetAcid().Tempo)
+	is.Equal("new", rd.GetInfo().Title)
+	got, err = rd.ReadChunk(rd.Chunks()[3])
+	is.NoErr(err)
+	is.Equal([]byte{0, 0x80}, got[6:8])
+}
@@ -0,0 +1,140 @@
+package wav
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+)
+
+// Dither selects how samples are treated when they are quantized to fewer bits
+type Dither int
+
+const (
+	// DitherNone rounds to the nearest value
+	DitherNone Dither = iota
+	// DitherTPDF adds triangular noise of ±1 LSB before rounding
+	DitherTPDF
+	// DitherNoiseShaped adds TPDF dither and feeds the quantization error back,
+	// moving the noise towards high frequencies where it is less audible
+	DitherNoiseShaped
+)
+
+// SampleReader supplies samples as float64 in [-1, 1), Reader implements it
+type SampleReader interface {
+	GetFile() File
+	ReadFloat64() (float64, error)
+}
+
+// Quantizer turns float samples into integers of a given bit depth.
+// The state of the noise shaping is kept per channel.
+type Quantizer struct {
+	dither Dither
+	scale  float64
+	min    float64
+	max    float64
+	rng    *rand.Rand
+	errs   []float64 // last quantization error of each channel
+}
+
+// NewQuantizer returns a Quantizer for bits bit samples with the given number of channels.
+// The dither noise is generated from seed, so the output is deterministic.
+func NewQuantizer(bits uint16, channels int, dither Dither, seed int64) *Quantizer {
+	scale := math.Ldexp(1, int(bits)-1)
+	return &Quantizer{
+		dither: dither,
+		scale:  scale,
+		min:    -scale,
+		max:    scale - 1,
+		rng:    rand.New(rand.NewSource(seed)),
+		errs:   make([]float64, channels),
+	}
+}
+
+// Quantize returns x, a sample of channel ch in [-1, 1), as an integer
+func (q *Quantizer) Quantize(ch int, x float64) int32 {
+	v := x * q.scale
+
+	if q.dither == DitherNoiseShaped {
+		v -= q.errs[ch]
+	}
+
+	d := v
+	if q.dither != DitherNone {
+		d += q.rng.Float64() - q.rng.Float64()
+	}
+
+	y := math.Floor(d + 0.5)
+	if y > q.max {
+		y = q.max
+	} else if y < q.min {
+		y = q.min
+	}
+
+	if q.dither == DitherNoiseShaped {
+		q.errs[ch] = y - v
+	}
+
+	return int32(y)
+}
+
+// Convert copies all samples from src to dst, converting them to the format of dst.
+// Dither is only applied if dst has fewer bits than src, increasing the depth is lossless.
+// It returns the number of samples written.
+func Convert(dst *Writer, src SampleReader, dither Dither, seed int64) (n uint64, err error) {
+	in := src.GetFile()
+	if in.Channels == 0 {
+		return 0, fmt.Errorf("source has no channels")
+	}
+	if in.Channels != dst.options.Channels {
+		return 0, fmt.Errorf("channel count %d does not match %d", in.Channels, dst.options.Channels)
+	}
+
+	var (
+		channels = int(in.Channels)
+		isFloat  = dst.options.AudioFormat == AudioFormatIEEEFloat
+		bits     = resolution(dst.options)
+		q        *Quantizer
+	)
+	if !isFloat {
+		if bits >= resolution(in) {
+			dither = DitherNone
+		}
+		q = NewQuantizer(bits, channels, dither, seed)
+	}
+
+	for ch := 0; ; ch = (ch + 1) % channels {
+		x, err := src.ReadFloat64()
+		if err == io.EOF {
+			return n, nil
+		} else if err != nil {
+			return n, err
+		}
+
+		if isFloat {
+			err = dst.WriteFloat64(x)
+		} else {
+			err = dst.WriteInt32(q.Quantize(ch, x))
+		}
+		if err != nil {
+			return n, err
+		}
+		n++
+	}
+}
+
+// resolution returns the number of bits of the integer values of file,
+// float formats have the 53 bits of a float64 mantissa
+func resolution(file File) uint16 {
+	switch file.AudioFormat {
+	case AudioFormatIEEEFloat:
+		return 53
+	case AudioFormatALaw, AudioFormatMULaw, AudioFormatMSADPCM, AudioFormatIMAADPCM:
+		// decoded to and encoded from 16 bit linear samples
+		return 16
+	}
+	if file.ValidBits != 0 {
+		return file.ValidBits
+	}
+	return file.SignificantBits
+}
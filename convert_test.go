@@ -0,0 +1,142 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+// sliceReader supplies the samples of a slice
+type sliceReader struct {
+	file    File
+	samples []float64
+}
+
+func (s *sliceReader) GetFile() File { return s.file }
+
+func (s *sliceReader) ReadFloat64() (float64, error) {
+	if len(s.samples) == 0 {
+		return 0, io.EOF
+	}
+	x := s.samples[0]
+	s.samples = s.samples[1:]
+	return x, nil
+}
+
+func TestQuantizer_none(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	q := NewQuantizer(16, 1, DitherNone, 0)
+	is.Equal(int32(0), q.Quantize(0, 0))
+	is.Equal(int32(16384), q.Quantize(0, 0.5))
+	is.Equal(int32(-32768), q.Quantize(0, -1))
+	is.Equal(int32(32767), q.Quantize(0, 1))
+	is.Equal(int32(-32768), q.Quantize(0, -2))
+	is.Equal(int32(1), q.Quantize(0, 0.6/32768))
+}
+
+func TestQuantizer_deterministic(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	for _, d := range []Dither{DitherTPDF, DitherNoiseShaped} {
+		a := NewQuantizer(8, 2, d, 42)
+		b := NewQuantizer(8, 2, d, 42)
+		for i := 0; i < 1000; i++ {
+			x := math.Sin(float64(i) / 10)
+			is.Equal(a.Quantize(i%2, x), b.Quantize(i%2, x))
+		}
+	}
+}
+
+func TestQuantizer_tpdf(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	// a constant between two steps averages to its true value and stays within ±1 LSB
+	const x = 0.25 / 128
+	q := NewQuantizer(8, 1, DitherTPDF, 1)
+	var sum int32
+	const n = 10000
+	for i := 0; i < n; i++ {
+		y := q.Quantize(0, x)
+		is.True(y >= -1 && y <= 2)
+		sum += y
+	}
+	mean := float64(sum) / n
+	is.True(math.Abs(mean-0.25) < 0.05)
+}
+
+func TestQuantizer_noiseShaped(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	// the error feedback keeps the running sum of the output close to the input
+	const x = 0.3 / 128
+	q := NewQuantizer(8, 1, DitherNoiseShaped, 1)
+	var sum float64
+	for i := 1; i <= 1000; i++ {
+		sum += float64(q.Quantize(0, x)) - 0.3
+		is.True(math.Abs(sum) < 4)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	src := &sliceReader{
+		file:    File{Channels: 2, SampleRate: 8000, SignificantBits: 24},
+		samples: []float64{0, 0.5, -1, 1.0 / 256},
+	}
+
+	var b bytes.Buffer
+	wr, err := File{Channels: 2, SampleRate: 8000, SignificantBits: 16}.NewRawWriter(&b)
+	is.NoErr(err)
+	n, err := Convert(wr, src, DitherNone, 0)
+	is.NoErr(err)
+	is.Equal(uint64(4), n)
+	is.NoErr(wr.Close())
+
+	var got [4]int16
+	is.NoErr(binary.Read(&b, binary.LittleEndian, &got))
+	is.Equal([4]int16{0, 16384, -32768, 128}, got)
+}
+
+func TestConvert_float(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	buf := pcmWave(24, 0, []byte{0x00, 0x00, 0x80, 0x00, 0x00, 0x40})
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+
+	var b bytes.Buffer
+	wr, err := File{Channels: 1, SampleRate: 8000, SignificantBits: 32, AudioFormat: AudioFormatIEEEFloat}.NewRawWriter(&b)
+	is.NoErr(err)
+	_, err = Convert(wr, rd, DitherTPDF, 0)
+	is.NoErr(err)
+	is.NoErr(wr.Close())
+
+	var got [2]float32
+	is.NoErr(binary.Read(&b, binary.LittleEndian, &got))
+	is.Equal([2]float32{-1, 0.5}, got)
+}
+
+func TestConvert_channels(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var b bytes.Buffer
+	wr, err := File{Channels: 1, SampleRate: 8000, SignificantBits: 16}.NewRawWriter(&b)
+	is.NoErr(err)
+	_, err = Convert(wr, &sliceReader{file: File{Channels: 2}}, DitherNone, 0)
+	is.Err(err)
+
+	wr.options.Channels = 0
+	_, err = Convert(wr, &sliceReader{file: File{}, samples: []float64{1}}, DitherNone, 0)
+	is.Err(err)
+}
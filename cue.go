@@ -0,0 +1,207 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+var (
+	tokenCue  = [4]byte{'c', 'u', 'e', ' '}
+	tokenAdtl = [4]byte{'a', 'd', 't', 'l'}
+	tokenLabl = [4]byte{'l', 'a', 'b', 'l'}
+	tokenNote = [4]byte{'n', 'o', 't', 'e'}
+	tokenLtxt = [4]byte{'l', 't', 'x', 't'}
+	tokenRgn  = [4]byte{'r', 'g', 'n', ' '}
+)
+
+// Cue is a marker in the samples, from the cue chunk and the labl, note and ltxt entries of LIST/adtl.
+// Cues with a Length are regions.
+type Cue struct {
+	ID       uint32
+	Position uint32 // sample frame of the marker
+	Label    string
+	Note     string
+
+	// from the labelled text, Purpose defaults to "rgn " for regions
+	Length  uint32 // in sample frames
+	Purpose string
+	Text    string
+}
+
+// adtlEntry is a labl, note or ltxt entry until it is merged with its cue point
+type adtlEntry struct {
+	kind [4]byte
+	cue  Cue
+}
+
+// 24 byte cue point
+type cuePoint struct {
+	ID           uint32
+	Position     uint32
+	DataChunkID  [4]byte
+	ChunkStart   uint32
+	BlockStart   uint32
+	SampleOffset uint32
+}
+
+// 20 bytes in front of the text of a ltxt entry
+type ltxtHeader struct {
+	ID           uint32
+	SampleLength uint32
+	Purpose      [4]byte
+	Country      uint16
+	Language     uint16
+	Dialect      uint16
+	CodePage     uint16
+}
+
+// GetCues returns the markers and regions of the file
+func (wav *Reader) GetCues() []Cue {
+	return wav.cues
+}
+
+// parseChunkCue reads a cue chunk of chunkSize bytes, broken chunks are ignored
+func (wav *Reader) parseChunkCue(chunkSize uint32) error {
+	pos, err := wav.input.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return err
+	}
+	if chunkSize < 4 || int64(chunkSize) > wav.size-pos {
+		return nil
+	}
+
+	body := make([]byte, chunkSize)
+	if _, err = io.ReadFull(wav.input, body); err != nil {
+		return err
+	}
+
+	count := wav.order.Uint32(body)
+	if uint64(count)*24 > uint64(chunkSize-4) {
+		return nil
+	}
+
+	points := make([]cuePoint, count)
+	binary.Read(bytes.NewReader(body[4:]), wav.order, points)
+
+	wav.cues = make([]Cue, count)
+	for i, p := range points {
+		wav.cues[i] = Cue{ID: p.ID, Position: p.SampleOffset}
+	}
+	return nil
+}
+
+// parseAdtl keeps the entries of a LIST/adtl chunk, they are matched with the cue points by mergeCues
+func (wav *Reader) parseAdtl(b []byte) {
+	for len(b) >= 8 {
+		var id [4]byte
+		copy(id[:], b)
+		size := wav.order.Uint32(b[4:])
+		b = b[8:]
+		if uint64(size) > uint64(len(b)) {
+			break
+		}
+
+		body := b[:size]
+		b = b[size:]
+		if size&1 == 1 && len(b) > 0 {
+			b = b[1:]
+		}
+
+		if len(body) < 4 {
+			continue
+		}
+		cue := Cue{ID: wav.order.Uint32(body)}
+		switch id {
+		case tokenLabl:
+			cue.Label = cString(body[4:])
+		case tokenNote:
+			cue.Note = cString(body[4:])
+		case tokenLtxt:
+			var h ltxtHeader
+			if binary.Read(bytes.NewReader(body), wav.order, &h) != nil {
+				continue
+			}
+			cue.Length = h.SampleLength
+			cue.Purpose = cString(h.Purpose[:])
+			cue.Text = cString(body[20:])
+		default:
+			continue
+		}
+		wav.adtl = append(wav.adtl, adtlEntry{id, cue})
+	}
+}
+
+// mergeCues adds the texts of the adtl entries to the cue points with the same ID
+func (wav *Reader) mergeCues() {
+	for _, e := range wav.adtl {
+		a := e.cue
+		for i := range wav.cues {
+			c := &wav.cues[i]
+			if c.ID != a.ID {
+				continue
+			}
+			switch e.kind {
+			case tokenLabl:
+				c.Label = a.Label
+			case tokenNote:
+				c.Note = a.Note
+			case tokenLtxt:
+				c.Length, c.Purpose, c.Text = a.Length, a.Purpose, a.Text
+			}
+		}
+	}
+	wav.adtl = nil
+}
+
+// cueChunks returns the cue chunk and, if there are any texts, the LIST/adtl chunk
+func cueChunks(cues []Cue, order binary.ByteOrder) []byte {
+	var body, adtl bytes.Buffer
+	binary.Write(&body, order, uint32(len(cues)))
+	adtl.Write(tokenAdtl[:])
+
+	for _, c := range cues {
+		binary.Write(&body, order, cuePoint{
+			ID:           c.ID,
+			Position:     c.Position,
+			DataChunkID:  tokenData,
+			SampleOffset: c.Position,
+		})
+
+		if c.Label != "" {
+			writeChunk(&adtl, order, tokenLabl, cueText(order, c.ID, c.Label))
+		}
+		if c.Note != "" {
+			writeChunk(&adtl, order, tokenNote, cueText(order, c.ID, c.Note))
+		}
+		if c.Length != 0 || c.Purpose != "" || c.Text != "" {
+			h := ltxtHeader{ID: c.ID, SampleLength: c.Length, Purpose: tokenRgn}
+			if c.Purpose != "" {
+				copy(h.Purpose[:], c.Purpose+"    ")
+			}
+			var ltxt bytes.Buffer
+			binary.Write(&ltxt, order, h)
+			if c.Text != "" {
+				ltxt.WriteString(c.Text)
+				ltxt.WriteByte(0)
+			}
+			writeChunk(&adtl, order, tokenLtxt, ltxt.Bytes())
+		}
+	}
+
+	var b bytes.Buffer
+	writeChunk(&b, order, tokenCue, body.Bytes())
+	if adtl.Len() > 4 {
+		writeChunk(&b, order, tokenList, adtl.Bytes())
+	}
+	return b.Bytes()
+}
+
+// cueText returns the body of a labl or note entry
+func cueText(order binary.ByteOrder, id uint32, text string) []byte {
+	b := make([]byte, 4, 4+len(text)+1)
+	order.PutUint32(b, id)
+	b = append(b, text...)
+	return append(b, 0)
+}
@@ -0,0 +1,111 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestParseCues(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	// the labels come before the cue points
+	var adtl bytes.Buffer
+	adtl.Write(tokenAdtl[:])
+	writeChunk(&adtl, binary.LittleEndian, tokenLabl, cueText(binary.LittleEndian, 2, "Intro"))
+	writeChunk(&adtl, binary.LittleEndian, tokenNote, cueText(binary.LittleEndian, 2, "cut here"))
+	writeChunk(&adtl, binary.LittleEndian, tokenLabl, cueText(binary.LittleEndian, 9, "no cue"))
+	var ltxt bytes.Buffer
+	binary.Write(&ltxt, binary.LittleEndian, ltxtHeader{ID: 1, SampleLength: 480, Purpose: tokenRgn})
+	ltxt.WriteString("Ad\x00")
+	writeChunk(&adtl, binary.LittleEndian, tokenLtxt, ltxt.Bytes())
+
+	var cue bytes.Buffer
+	binary.Write(&cue, binary.LittleEndian, uint32(2))
+	binary.Write(&cue, binary.LittleEndian, cuePoint{ID: 1, Position: 100, DataChunkID: tokenData, SampleOffset: 100})
+	binary.Write(&cue, binary.LittleEndian, cuePoint{ID: 2, Position: 0, DataChunkID: tokenData, SampleOffset: 0})
+
+	var c bytes.Buffer
+	writeChunk(&c, binary.LittleEndian, tokenList, adtl.Bytes())
+	writeChunk(&c, binary.LittleEndian, tokenCue, cue.Bytes())
+	buf := infoWave(c.Bytes())
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal([]Cue{
+		{ID: 1, Position: 100, Length: 480, Purpose: "rgn ", Text: "Ad"},
+		{ID: 2, Position: 0, Label: "Intro", Note: "cut here"},
+	}, rd.GetCues())
+	is.Equal(rd.GetCues(), rd.GetFile().Cues)
+
+	// a cue chunk with too many points is ignored
+	c.Reset()
+	writeChunk(&c, binary.LittleEndian, tokenCue, []byte{0xff, 0, 0, 0})
+	buf = infoWave(c.Bytes())
+	rd, err = NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Nil(rd.GetCues())
+}
+
+func TestWriteReadCues(t *testing.T) {
+	is := is.New(t)
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+	defer os.Remove(f.Name())
+
+	cues := []Cue{
+		{ID: 1, Position: 0, Label: "Start"},
+		{ID: 2, Position: 4410, Label: "Chapter 2", Note: "loud"},
+		{ID: 3, Position: 8820, Length: 2205, Purpose: "rgn ", Text: "Sponsor"},
+		{ID: 4, Position: 9000},
+	}
+	meta := File{
+		Channels:        1,
+		SampleRate:      44100,
+		SignificantBits: 16,
+		Cues:            cues,
+	}
+	wr, err := meta.NewWriter(f)
+	is.NoErr(err)
+	is.NoErr(wr.WriteInt32(3))
+	is.NoErr(wr.Close())
+
+	buf, err := ioutil.ReadFile(f.Name())
+	is.NoErr(err)
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(cues, rd.GetCues())
+
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(3), s)
+}
+
+func TestParseCues_broken(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	// the size of the trailing cue chunk exceeds the file
+	buf := infoWave([]byte{'c', 'u', 'e', ' ', 0, 0, 0, 0xf0, 0, 0, 0, 0x0a, 1, 2, 3, 4})
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Nil(rd.GetCues())
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(1), s)
+
+	// more cue points than the chunk holds
+	var c bytes.Buffer
+	writeChunk(&c, binary.LittleEndian, tokenCue, []byte{2, 0, 0, 0, 1, 0, 0, 0})
+	buf = infoWave(c.Bytes())
+	rd, err = NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Nil(rd.GetCues())
+}
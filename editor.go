@@ -0,0 +1,244 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"os"
+)
+
+// Editor changes the metadata of a WAV file in place, without copying the samples
+type Editor struct {
+	f    io.ReadWriteSeeker
+	size int64
+	rd   *Reader
+}
+
+// span is a range of free bytes in the file
+type span struct {
+	start, end int64
+}
+
+// NewEditor opens the RIFF, RIFX, RF64 or BW64 file f of size bytes for editing.
+// If f has a Truncate method, like *os.File, the file shrinks when chunks at its end are removed.
+func NewEditor(f io.ReadWriteSeeker, size int64) (*Editor, error) {
+	rd, err := NewReader(f, size)
+	if err != nil {
+		return nil, err
+	}
+	if rd.header.Ftype == tokenW64Riff {
+		return nil, ErrNotRiff
+	}
+	return &Editor{f: f, size: size, rd: rd}, nil
+}
+
+// File returns the format and metadata of the file, which are changed and passed to Save
+func (e *Editor) File() File {
+	return e.rd.GetFile()
+}
+
+// Size returns the size of the file in bytes
+func (e *Editor) Size() int64 {
+	return e.size
+}
+
+// Save replaces the metadata of the file with the metadata fields and Chunks of meta, the format is ignored.
+// Unchanged chunks stay where they are. New and changed chunks are written to JUNK or PAD chunks and
+// the space of removed chunks where they fit, otherwise they are appended to the file.
+// A JUNK chunk in front of the fmt chunk is kept, it reserves the space of the ds64 chunk of RF64.
+// The fmt, fact and data chunks are never touched.
+// The Chunks of meta are read from the file, so meta has to come from File after the last Save.
+func (e *Editor) Save(meta File) error {
+	for _, c := range meta.Chunks {
+		if c.Data == nil && c.src != nil && c.src != e.rd && c.src.input == e.rd.input {
+			return ErrStaleMetadata
+		}
+	}
+
+	order := e.rd.order
+	front, back, err := meta.metadata(order)
+	if err != nil {
+		return err
+	}
+
+	var pending []RawChunk
+	for _, c := range append(splitChunks(front, order), splitChunks(back, order)...) {
+		if c.ID != "JUNK" && c.ID != "PAD " {
+			pending = append(pending, c)
+		}
+	}
+
+	// the chunks which are not pending are free space
+	var free []span
+	end := e.size
+	fmtSeen := false
+	for _, c := range e.rd.Chunks() {
+		start := c.Offset - 8
+		stop := c.Offset + c.Size + c.Size&1
+		if stop > end {
+			end = stop
+		}
+
+		switch c.ID {
+		case "fmt ":
+			fmtSeen = true
+			continue
+		case "fact", "data", "ds64":
+			continue
+		case "JUNK", "PAD ":
+			if !fmtSeen && c.ID == "JUNK" {
+				continue
+			}
+		default:
+			body, err := e.rd.ReadChunk(c)
+			if err != nil && err != io.ErrUnexpectedEOF {
+				return err
+			}
+			if i := indexChunk(pending, c.ID, body); err == nil && i >= 0 {
+				pending = append(pending[:i], pending[i+1:]...)
+				continue
+			}
+		}
+
+		if n := len(free); n > 0 && free[n-1].end == start {
+			free[n-1].end = stop
+		} else {
+			free = append(free, span{start, stop})
+		}
+	}
+
+	// chunks are appended to the free space at the end of the file
+	tail := end
+	if n := len(free); n > 0 && free[n-1].end == end {
+		tail = free[n-1].start
+		free = free[:n-1]
+	}
+
+	var b bytes.Buffer
+	writes := make(map[int64][]byte)
+	for _, c := range pending {
+		b.Reset()
+		var id [4]byte
+		copy(id[:], c.ID+"    ")
+		writeChunk(&b, order, id, c.Data)
+		n := int64(b.Len())
+
+		pos := int64(-1)
+		for i := range free {
+			// the rest has to hold a JUNK chunk
+			if size := free[i].end - free[i].start; size == n || size >= n+8 {
+				pos = free[i].start
+				free[i].start += n
+				break
+			}
+		}
+		if pos < 0 {
+			pos = tail
+			tail += n
+		}
+		writes[pos] = append([]byte(nil), b.Bytes()...)
+	}
+
+	newSize := tail
+	truncater, canTruncate := e.f.(interface {
+		Truncate(int64) error
+	})
+	if tail < end && !canTruncate {
+		// a gap too small for a JUNK chunk grows the file
+		junk := span{tail, end}
+		if junk.end-junk.start < 8 {
+			junk.end = junk.start + 8
+		}
+		free = append(free, junk)
+		newSize = junk.end
+	}
+	for _, s := range free {
+		if s.end > s.start {
+			writes[s.start] = junkChunk(order, s.end-s.start)
+		}
+	}
+
+	rf64 := e.rd.ds64 != nil
+	if !rf64 && newSize-8 > math.MaxUint32 {
+		return ErrInputToLarge
+	}
+
+	// a missing pad byte of the last chunk
+	if newSize > e.size {
+		if err := e.writeAt(e.size, make([]byte, newSize-e.size)); err != nil {
+			return err
+		}
+	}
+	for pos, b := range writes {
+		if err := e.writeAt(pos, b); err != nil {
+			return err
+		}
+	}
+
+	var size bytes.Buffer
+	if rf64 {
+		binary.Write(&size, order, uint64(newSize-8))
+		if err := e.writeAt(20, size.Bytes()); err != nil {
+			return err
+		}
+	} else {
+		binary.Write(&size, order, uint32(newSize-8))
+		if err := e.writeAt(4, size.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if newSize < e.size {
+		if err := truncater.Truncate(newSize); err != nil {
+			return err
+		}
+	}
+
+	if _, err := e.f.Seek(0, os.SEEK_SET); err != nil {
+		return err
+	}
+	rd, err := NewReader(e.f, newSize)
+	if err != nil {
+		return err
+	}
+	e.rd, e.size = rd, newSize
+	return nil
+}
+
+func (e *Editor) writeAt(pos int64, b []byte) error {
+	if _, err := e.f.Seek(pos, os.SEEK_SET); err != nil {
+		return err
+	}
+	_, err := e.f.Write(b)
+	return err
+}
+
+// indexChunk returns the index of the chunk with id and body in chunks, -1 if there is none
+func indexChunk(chunks []RawChunk, id string, body []byte) int {
+	for i, c := range chunks {
+		if c.ID == id && bytes.Equal(c.Data, body) {
+			return i
+		}
+	}
+	return -1
+}
+
+// junkChunk returns a zeroed JUNK chunk of size bytes including the header
+func junkChunk(order binary.ByteOrder, size int64) []byte {
+	b := make([]byte, size)
+	copy(b, tokenJunk[:])
+	order.PutUint32(b[4:], uint32(size-8))
+	return b
+}
+
+// splitChunks cuts the chunks written by writeChunk apart
+func splitChunks(b []byte, order binary.ByteOrder) []RawChunk {
+	var chunks []RawChunk
+	for len(b) >= 8 {
+		size := int(order.Uint32(b[4:]))
+		chunks = append(chunks, RawChunk{ID: string(b[:4]), Data: b[8 : 8+size]})
+		b = b[8+size+size&1:]
+	}
+	return chunks
+}
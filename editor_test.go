@@ -0,0 +1,206 @@
+package wav
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+// editorFile writes a file with a bext and an INFO chunk and opens it for editing
+func editorFile(t *testing.T) (*os.File, *Editor) {
+	is := is.New(t)
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+
+	meta := File{
+		Channels:        1,
+		SampleRate:      44100,
+		SignificantBits: 16,
+		Bext:            &Bext{Description: "take 1"},
+		Info:            &Info{Title: "first"},
+	}
+	wr, err := meta.NewWriter(f)
+	is.NoErr(err)
+	for _, s := range []int32{1, -2, 3} {
+		is.NoErr(wr.WriteInt32(s))
+	}
+	is.NoErr(wr.Close())
+
+	f, err = os.OpenFile(f.Name(), os.O_RDWR, 0)
+	is.NoErr(err)
+	fi, err := f.Stat()
+	is.NoErr(err)
+	e, err := NewEditor(f, fi.Size())
+	is.NoErr(err)
+	return f, e
+}
+
+// checkEditor reads the file again and compares the samples
+func checkEditor(t *testing.T, f *os.File) *Reader {
+	is := is.New(t)
+
+	buf, err := ioutil.ReadFile(f.Name())
+	is.NoErr(err)
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	for _, want := range []int32{1, -2, 3} {
+		s, err := rd.ReadSample()
+		is.NoErr(err)
+		is.Equal(want, s)
+	}
+	return rd
+}
+
+func chunkIDs(rd *Reader) string {
+	var ids []string
+	for _, c := range rd.Chunks() {
+		ids = append(ids, c.ID)
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestEditor_inPlace(t *testing.T) {
+	is := is.New(t)
+	f, e := editorFile(t)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	size := e.Size()
+	before := checkEditor(t, f).FirstSampleOffset()
+
+	// the bext chunk keeps its size, the inst chunk doesn't go into the reserved JUNK chunk
+	meta := e.File()
+	meta.Bext = &Bext{Description: "take 2"}
+	meta.Instrument = &Instrument{UnshiftedNote: 60, HighNote: 127, HighVelocity: 127}
+	is.NoErr(e.Save(meta))
+	is.Equal(size+16, e.Size())
+
+	rd := checkEditor(t, f)
+	is.Equal(before, rd.FirstSampleOffset())
+	is.Equal("JUNK,fmt ,bext,LIST,data,inst", chunkIDs(rd))
+	is.Equal("take 2", rd.GetBext().Description)
+	is.Equal("first", rd.GetInfo().Title)
+	is.Equal(uint8(60), rd.GetInstrument().UnshiftedNote)
+
+	// the copied chunks are read from the file
+	got, want := rd.GetFile(), e.File()
+	is.Equal(len(want.Chunks), len(got.Chunks))
+	for i := range want.Chunks {
+		a, err := want.Chunks[i].Body()
+		is.NoErr(err)
+		b, err := got.Chunks[i].Body()
+		is.NoErr(err)
+		is.Equal(a, b)
+	}
+	got.Chunks, want.Chunks = nil, nil
+	is.Equal(want, got)
+
+	// the chunks of meta have moved
+	is.Equal(ErrStaleMetadata, e.Save(meta))
+}
+
+func TestEditor_append(t *testing.T) {
+	is := is.New(t)
+	f, e := editorFile(t)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	size := e.Size()
+
+	// a longer title and an iXML document don't fit in front of the samples, DISP fits in place of the old LIST
+	meta := e.File()
+	meta.Info.Title = "a much longer title than before"
+	meta.IXML = &IXML{Project: "edit"}
+	meta.Chunks = append(meta.Chunks, RawChunk{ID: "DISP", Data: []byte{1, 0, 0, 0, 'x'}})
+	is.NoErr(e.Save(meta))
+	is.True(e.Size() > size)
+
+	rd := checkEditor(t, f)
+	is.Equal("JUNK,fmt ,bext,DISP,JUNK,data,LIST,iXML", chunkIDs(rd))
+	is.Equal("a much longer title than before", rd.GetInfo().Title)
+	is.Equal("edit", rd.GetIXML().Project)
+	body, err := rd.ReadChunk(rd.Chunks()[3])
+	is.NoErr(err)
+	is.Equal([]byte{1, 0, 0, 0, 'x'}, body)
+
+	// the removed chunks at the end are cut off
+	meta = e.File()
+	meta.IXML = nil
+	var chunks []RawChunk
+	for _, c := range meta.Chunks {
+		if c.ID != "DISP" {
+			chunks = append(chunks, c)
+		}
+	}
+	meta.Chunks = chunks
+	is.NoErr(e.Save(meta))
+
+	rd = checkEditor(t, f)
+	is.Equal("JUNK,fmt ,bext,JUNK,data,LIST", chunkIDs(rd))
+	is.Nil(rd.GetIXML())
+	fi, err := f.Stat()
+	is.NoErr(err)
+	is.Equal(e.Size(), fi.Size())
+}
+
+// rwBuffer is an in-memory file without a Truncate method
+type rwBuffer struct {
+	*bytes.Reader
+	b []byte
+}
+
+func (rw *rwBuffer) Write(p []byte) (int, error) {
+	pos, _ := rw.Seek(0, os.SEEK_CUR)
+	if end := int(pos) + len(p); end > len(rw.b) {
+		rw.b = append(rw.b, make([]byte, end-len(rw.b))...)
+	}
+	copy(rw.b[pos:], p)
+	rw.Reader = bytes.NewReader(rw.b)
+	_, err := rw.Seek(pos+int64(len(p)), os.SEEK_SET)
+	return len(p), err
+}
+
+func TestEditor_noTruncate(t *testing.T) {
+	is := is.New(t)
+
+	buf := infoWave()
+	rw := &rwBuffer{bytes.NewReader(buf), buf}
+	e, err := NewEditor(rw, int64(len(buf)))
+	is.NoErr(err)
+
+	meta := e.File()
+	meta.Acid = &Acid{Beats: 4, Tempo: 100}
+	is.NoErr(e.Save(meta))
+	is.Equal(int64(len(buf)+32), e.Size())
+
+	// the smaller chunk in place of acid leaves four bytes, which are too few for a JUNK chunk
+	meta = e.File()
+	meta.Acid = nil
+	meta.Chunks = append(meta.Chunks, RawChunk{ID: "ABCD", Data: make([]byte, 20), AfterData: true})
+	is.NoErr(e.Save(meta))
+	is.Equal(int64(len(buf)+36), e.Size())
+
+	rd, err := NewReader(bytes.NewReader(rw.b), int64(len(rw.b)))
+	is.NoErr(err)
+	is.Equal("fmt ,data,ABCD,JUNK", chunkIDs(rd))
+	is.Nil(rd.GetAcid())
+
+	// the removed chunks become JUNK
+	is.NoErr(e.Save(File{}))
+	is.Equal(int64(len(buf)+36), e.Size())
+
+	rd, err = NewReader(bytes.NewReader(rw.b), int64(len(rw.b)))
+	is.NoErr(err)
+	is.Equal("fmt ,data,JUNK", chunkIDs(rd))
+	body, err := rd.ReadChunk(rd.Chunks()[2])
+	is.NoErr(err)
+	is.Equal(make([]byte, 28), body)
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(1), s)
+}
@@ -10,19 +10,43 @@ var (
 	ErrInputToLarge = errors.New("Input too large")
 	// ErrNotRiff error
 	ErrNotRiff = errors.New("Not a RIFF file")
+	// ErrNotAIFF error
+	ErrNotAIFF = errors.New("Not an AIFF file")
+	// ErrNotCAF error
+	ErrNotCAF = errors.New("Not a CAF file")
 	// ErrNotWave error
 	ErrNotWave = errors.New("Not a WAVE file")
+	// ErrBrokenChunkDS64 error
+	ErrBrokenChunkDS64 = errors.New("could not decode chunkDS64")
+	// ErrBrokenChunkFact error
+	ErrBrokenChunkFact = errors.New("could not decode chunkFact")
+	// ErrBrokenChunkComm error
+	ErrBrokenChunkComm = errors.New("could not decode chunkComm")
+	// ErrBrokenChunkSsnd error
+	ErrBrokenChunkSsnd = errors.New("could not decode chunkSsnd")
+	// ErrBrokenChunkDesc error
+	ErrBrokenChunkDesc = errors.New("could not decode chunkDesc")
+	// ErrBrokenChunkPakt error
+	ErrBrokenChunkPakt = errors.New("could not decode chunkPakt")
 	// ErrBrokenChunkFmt error
 	ErrBrokenChunkFmt = errors.New("could not decode chunkFmt")
 	// ErrNoBitsPerSample error
 	ErrNoBitsPerSample = errors.New("could not decode chunkFmt")
 	// ErrFormatNotSupported error
-	ErrFormatNotSupported = errors.New("Format not supported - Only uncompressed PCM currently")
+	ErrFormatNotSupported = errors.New("Format not supported - Only uncompressed PCM, IEEE float, G.711 and ADPCM currently")
+	// ErrBrokenBlock error
+	ErrBrokenBlock = errors.New("could not decode compressed block")
+	// ErrNotSeekable error, seeking backwards in a raw stream which is no io.Seeker
+	ErrNotSeekable = errors.New("raw stream is not seekable")
+	// ErrSampleType error
+	ErrSampleType = errors.New("Sample type does not match the audio format")
+	// ErrStaleMetadata error, the chunks passed to Editor.Save were read before the file was changed
+	ErrStaleMetadata = errors.New("metadata is from before the last Save")
 )
 
 // ErrIncorrectChunkSize struct
 type ErrIncorrectChunkSize struct {
-	Got, Wanted uint32
+	Got, Wanted int64
 }
 
 func (e ErrIncorrectChunkSize) Error() string {
@@ -34,44 +34,25 @@ func main() {
 		log.Fatal("Please use a 2-channel audio file")
 	}
 
-	// A slice of many 1-4 byte samples
-	var left [][]byte
-	left = make([][]byte, fileMeta.NumberOfSamples/2)
-
-	var i uint32
-	var buf []byte
-	for i = 0; i < fileMeta.NumberOfSamples/2; i++ {
-
-		buf, err = wavReader.ReadRawSample()
-		checkErr(err)
-		left[i] = buf
-
-		// Throw the right-channel away
-		_, err = wavReader.ReadRawSample()
-		checkErr(err)
-	}
-
 	filename := "mono-" + testInfo.Name()
 	os.Remove(filename)
 
 	f, err := os.Create(filename)
 	checkErr(err)
 
+	// Keep the left channel and throw the right one away
+	left, err := wav.NewRemixer(wavReader, wav.SelectChannels(2, 0))
+	checkErr(err)
+
 	// Create the headers for our new mono file
-	meta := wav.File{
-		Channels:        1,
-		SampleRate:      fileMeta.SampleRate,
-		SignificantBits: fileMeta.SignificantBits,
-	}
+	meta := left.GetFile()
 
 	writer, err := meta.NewWriter(f)
 	checkErr(err)
 
 	// Write to file
-	for _, sample := range left {
-		err = writer.WriteSample(sample)
-		checkErr(err)
-	}
+	_, err = wav.Convert(writer, left, wav.DitherNone, 0)
+	checkErr(err)
 
 	err = writer.Close()
 	checkErr(err)
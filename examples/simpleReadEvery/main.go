@@ -42,7 +42,7 @@ func main() {
 		log.Fatal(err)
 	}
 
-	fmt.Printf("Samples found %d, Estimated: %d\n", len(samples), meta.NumberOfSamples/readSampleRate+1)
+	fmt.Printf("Samples found %d, Estimated: %d\n", len(samples), meta.NumberOfSamples/uint64(readSampleRate)+1)
 
 	var second uint32
 	for i, sample := range samples {
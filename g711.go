@@ -0,0 +1,122 @@
+package wav
+
+// G.711 A-law and μ-law companding, after the Sun Microsystems reference implementation
+
+var (
+	alawDecode [256]int16
+	ulawDecode [256]int16
+
+	segAEnd = [8]int16{0x1F, 0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF}
+	segUEnd = [8]int16{0x3F, 0x7F, 0xFF, 0x1FF, 0x3FF, 0x7FF, 0xFFF, 0x1FFF}
+)
+
+const (
+	g711QuantMask = 0x0F
+	g711SegMask   = 0x70
+	g711SegShift  = 4
+	g711SignBit   = 0x80
+
+	ulawBias = 0x84
+	ulawClip = 8159
+)
+
+func init() {
+	for i := range alawDecode {
+		alawDecode[i] = alawToLinear(byte(i))
+		ulawDecode[i] = ulawToLinear(byte(i))
+	}
+}
+
+// segment returns the index of the first table entry >= v
+func segment(v int16, table *[8]int16) int {
+	for i, end := range table {
+		if v <= end {
+			return i
+		}
+	}
+	return len(table)
+}
+
+// linearToALaw encodes a 16 bit linear sample as A-law
+func linearToALaw(pcm int16) byte {
+	var mask byte
+	pcm >>= 3
+	if pcm >= 0 {
+		mask = 0xD5
+	} else {
+		mask = 0x55
+		pcm = -pcm - 1
+	}
+
+	seg := segment(pcm, &segAEnd)
+	if seg >= 8 {
+		return 0x7F ^ mask
+	}
+
+	aval := byte(seg) << g711SegShift
+	if seg < 2 {
+		aval |= byte(pcm>>1) & g711QuantMask
+	} else {
+		aval |= byte(pcm>>uint(seg)) & g711QuantMask
+	}
+	return aval ^ mask
+}
+
+// alawToLinear decodes an A-law sample to 16 bit linear
+func alawToLinear(a byte) int16 {
+	a ^= 0x55
+
+	t := int16(a&g711QuantMask) << 4
+	seg := (a & g711SegMask) >> g711SegShift
+	switch seg {
+	case 0:
+		t += 8
+	case 1:
+		t += 0x108
+	default:
+		t += 0x108
+		t <<= seg - 1
+	}
+
+	if a&g711SignBit != 0 {
+		return t
+	}
+	return -t
+}
+
+// linearToULaw encodes a 16 bit linear sample as μ-law
+func linearToULaw(pcm int16) byte {
+	var mask byte
+	pcm >>= 2
+	if pcm < 0 {
+		pcm = -pcm
+		mask = 0x7F
+	} else {
+		mask = 0xFF
+	}
+	if pcm > ulawClip {
+		pcm = ulawClip
+	}
+	pcm += ulawBias >> 2
+
+	seg := segment(pcm, &segUEnd)
+	if seg >= 8 {
+		return 0x7F ^ mask
+	}
+
+	uval := byte(seg)<<g711SegShift | byte(pcm>>uint(seg+1))&g711QuantMask
+	return uval ^ mask
+}
+
+// ulawToLinear decodes a μ-law sample to 16 bit linear
+func ulawToLinear(u byte) int16 {
+	u = ^u
+
+	t := int16(u&g711QuantMask)<<3 + ulawBias
+	t <<= (u & g711SegMask) >> g711SegShift
+
+	if u&g711SignBit != 0 {
+		return ulawBias - t
+	}
+	return t - ulawBias
+}
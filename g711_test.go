@@ -0,0 +1,84 @@
+package wav
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestG711_knownValues(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	is.Equal(byte(0xD5), linearToALaw(0))
+	is.Equal(byte(0xFF), linearToULaw(0))
+	is.Equal(int16(8), alawToLinear(0xD5))
+	is.Equal(int16(-8), alawToLinear(0x55))
+	is.Equal(int16(32256), alawToLinear(0xAA))
+	is.Equal(int16(-32256), alawToLinear(0x2A))
+	is.Equal(int16(32124), ulawToLinear(0x80))
+	is.Equal(int16(-32124), ulawToLinear(0x00))
+	is.Equal(byte(0xAA), linearToALaw(32767))
+	is.Equal(byte(0x80), linearToULaw(32767))
+	is.Equal(byte(0x00), linearToULaw(-32768))
+}
+
+func TestG711_roundTrip(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	for i := 0; i < 256; i++ {
+		a := alawDecode[i]
+		is.Equal(a, alawDecode[linearToALaw(a)])
+		u := ulawDecode[i]
+		is.Equal(u, ulawDecode[linearToULaw(u)])
+	}
+}
+
+func TestWriteRead_G711(t *testing.T) {
+	for _, format := range []uint16{AudioFormatALaw, AudioFormatMULaw} {
+		is := is.New(t)
+
+		f, err := ioutil.TempFile("", "wavPkgtest")
+		is.NoErr(err)
+
+		meta := File{
+			Channels:        1,
+			SampleRate:      8000,
+			SignificantBits: 8,
+			AudioFormat:     format,
+		}
+
+		wr, err := meta.NewWriter(f)
+		is.NoErr(err)
+
+		samples := []int32{0, 1000, -1000, 32767, -32768, 123456}
+		for _, s := range samples {
+			is.NoErr(wr.WriteInt32(s))
+		}
+		is.NoErr(wr.Close())
+
+		b, err := ioutil.ReadFile(f.Name())
+		is.NoErr(err)
+		is.True(bytes.Contains(b, []byte("fact\x04\x00\x00\x00\x06\x00\x00\x00")))
+
+		rd, err := NewReader(bytes.NewReader(b), int64(len(b)))
+		is.NoErr(err)
+		is.Equal(format, rd.GetAudioFormat())
+		is.Equal(uint64(len(samples)), rd.GetSampleCount())
+
+		for _, s := range samples {
+			n, err := rd.ReadSample()
+			is.NoErr(err)
+			// companding keeps the relative error small
+			diff := int32(clampInt16(s)) - n
+			if diff < 0 {
+				diff = -diff
+			}
+			is.True(diff <= 1024)
+		}
+
+		is.NoErr(os.Remove(f.Name()))
+	}
+}
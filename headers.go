@@ -1,29 +1,127 @@
 package wav
 
-import "time"
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+)
 
 const (
+	// maxSize of a plain RIFF file, larger files need RF64
 	maxSize = 2 << 31
 )
 
 var (
 	tokenRiff       = [4]byte{'R', 'I', 'F', 'F'}
+	tokenRifx       = [4]byte{'R', 'I', 'F', 'X'}
+	tokenRF64       = [4]byte{'R', 'F', '6', '4'}
+	tokenBW64       = [4]byte{'B', 'W', '6', '4'}
+	tokenDS64       = [4]byte{'d', 's', '6', '4'}
+	tokenJunk       = [4]byte{'J', 'U', 'N', 'K'}
 	tokenWaveFormat = [4]byte{'W', 'A', 'V', 'E'}
 	tokenChunkFmt   = [4]byte{'f', 'm', 't', ' '}
 	tokenData       = [4]byte{'d', 'a', 't', 'a'}
+	tokenFact       = [4]byte{'f', 'a', 'c', 't'}
+	tokenList       = [4]byte{'L', 'I', 'S', 'T'}
+	tokenInfo       = [4]byte{'I', 'N', 'F', 'O'}
+)
+
+// Audio formats as found in the AudioFormat field of the fmt chunk
+const (
+	// AudioFormatPCM is uncompressed integer PCM
+	AudioFormatPCM uint16 = 1
+	// AudioFormatMSADPCM is 4 bit Microsoft ADPCM, decoded to 16 bit linear samples
+	AudioFormatMSADPCM uint16 = 2
+	// AudioFormatIEEEFloat is uncompressed 32 or 64 bit IEEE 754 floating point
+	AudioFormatIEEEFloat uint16 = 3
+	// AudioFormatALaw is 8 bit G.711 A-law, decoded to 16 bit linear samples
+	AudioFormatALaw uint16 = 6
+	// AudioFormatMULaw is 8 bit G.711 μ-law, decoded to 16 bit linear samples
+	AudioFormatMULaw uint16 = 7
+	// AudioFormatIMAADPCM is 4 bit IMA/DVI ADPCM, decoded to 16 bit linear samples
+	AudioFormatIMAADPCM uint16 = 0x11
+	// AudioFormatExtensible marks a WAVE_FORMAT_EXTENSIBLE fmt chunk,
+	// the actual format is stored in its SubFormat GUID
+	AudioFormatExtensible uint16 = 0xFFFE
 )
 
+// GUID in the byte order of the file
+type GUID [16]byte
+
+func (g GUID) String() string {
+	return fmt.Sprintf("%08x-%04x-%04x-%x-%x",
+		binary.LittleEndian.Uint32(g[0:4]),
+		binary.LittleEndian.Uint16(g[4:6]),
+		binary.LittleEndian.Uint16(g[6:8]),
+		g[8:10], g[10:16])
+}
+
+// KSDATAFORMAT_SUBTYPE_* GUIDs are the format tag followed by this suffix
+var subFormatSuffix = [14]byte{0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71}
+
+// SubFormatGUID returns the SubFormat GUID of an extensible fmt chunk for the given audio format
+func SubFormatGUID(audioFormat uint16) (g GUID) {
+	binary.LittleEndian.PutUint16(g[0:2], audioFormat)
+	copy(g[2:], subFormatSuffix[:])
+	return g
+}
+
+// AudioFormat resolves a SubFormat GUID to the audio format it stands for
+func (g GUID) AudioFormat() (uint16, bool) {
+	var suffix [14]byte
+	copy(suffix[:], g[2:])
+	if suffix != subFormatSuffix {
+		return 0, false
+	}
+	return binary.LittleEndian.Uint16(g[0:2]), true
+}
+
 // File describes the WAV file
 type File struct {
 	SampleRate      uint32
 	SignificantBits uint16
 	Channels        uint16
-	NumberOfSamples uint32
+	NumberOfSamples uint64
 	Duration        time.Duration
 	AudioFormat     uint16
-	SoundSize       uint32
+	SoundSize       uint64
 	Canonical       bool
 	BytesPerSecond  uint32
+
+	// ValidBits, ChannelMask and SubFormat are taken from extensible fmt chunks.
+	// ValidBits of zero means all SignificantBits are valid.
+	ValidBits   uint16
+	ChannelMask uint32
+	SubFormat   GUID
+
+	// ByteOrder of the samples, nil for the native order of the container.
+	// That is little endian for WAV and big endian for AIFF.
+	// WAV files with big endian samples are RIFX files.
+	ByteOrder binary.ByteOrder
+
+	// Unsigned integer samples are stored as offset binary.
+	// WAV uses it for 8 bit samples only, the raw reader and writer take it as given.
+	Unsigned bool
+
+	// Info is the LIST/INFO metadata, nil if there is none
+	Info *Info
+	// Bext is the broadcast extension of BWF files, nil if there is none
+	Bext *Bext
+	// IXML is the production sound metadata, nil if there is none
+	IXML *IXML
+	// Cues are the markers and regions
+	Cues []Cue
+	// Sampler and Instrument hold the loops and key ranges for samplers, nil if there are none
+	Sampler    *Sampler
+	Instrument *Instrument
+	// ID3 is the tag of the id3 chunk
+	ID3 *ID3
+	// Acid holds the tempo and key of loops, nil if there is none
+	Acid *Acid
+
+	// Chunks are the other chunks of the file, which NewWriter copies.
+	// Known chunks are only copied if their field above is unchanged, otherwise the field is written.
+	Chunks []RawChunk
 }
 
 // 12 byte header
@@ -33,13 +131,35 @@ type riffHeader struct {
 	ChunkFormat [4]byte
 }
 
+// 28 byte body of the ds64 chunk in RF64 and BW64 files.
+// TableLength chunkSize64 entries follow.
+type riffChunkDS64 struct {
+	RiffSize    uint64
+	DataSize    uint64
+	SampleCount uint64
+	TableLength uint32
+}
+
+// 12 byte entry in the ds64 table, with the size of a chunk whose size field is 0xFFFFFFFF
+type chunkSize64 struct {
+	ChunkID [4]byte
+	Size    uint64
+}
+
 // 20
 type riffChunkFmt struct {
 	LengthOfHeader uint32
-	AudioFormat    uint16 // 1 = PCM not compressed
+	AudioFormat    uint16 // 1 = PCM not compressed, 3 = IEEE float
 	NumChannels    uint16
 	SampleRate     uint32
 	BytesPerSec    uint32
 	BytesPerBloc   uint16
 	BitsPerSample  uint16
 }
+
+// 22 bytes following cbSize in a WAVE_FORMAT_EXTENSIBLE fmt chunk
+type riffChunkFmtExtensible struct {
+	ValidBitsPerSample uint16
+	ChannelMask        uint32
+	SubFormat          GUID
+}
@@ -0,0 +1,324 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+var (
+	tokenID3      = [4]byte{'i', 'd', '3', ' '}
+	tokenID3Upper = [4]byte{'I', 'D', '3', ' '}
+)
+
+// ID3 is an ID3v2.3 or ID3v2.4 tag from an id3 chunk.
+// Compressed and encrypted frames are dropped, tags of other versions are ignored.
+type ID3 struct {
+	Version byte // 3 or 4, the Writer uses 4 if it is 0
+	Frames  []ID3Frame
+}
+
+// ID3Frame is a frame of an ID3 tag, Data is the body without unsynchronisation
+type ID3Frame struct {
+	ID   string // four characters, like TIT2 or APIC
+	Data []byte
+}
+
+// ID3Picture is the body of an APIC frame
+type ID3Picture struct {
+	MIMEType    string
+	Type        byte // 3 is the front cover
+	Description string
+	Data        []byte
+}
+
+// text encodings of ID3 frames
+const (
+	id3Latin1 byte = iota
+	id3UTF16
+	id3UTF16BE
+	id3UTF8
+)
+
+// GetID3 returns the ID3 tag of the id3 chunk, nil if the file has none
+func (wav *Reader) GetID3() *ID3 {
+	return wav.id3
+}
+
+// Text returns the value of the text frame id, like TIT2 for the title.
+// Multiple values are joined with a slash.
+func (t *ID3) Text(id string) string {
+	for _, f := range t.Frames {
+		if f.ID == id && len(f.Data) > 0 {
+			values := strings.Split(id3Decode(f.Data[0], f.Data[1:]), "\x00")
+			for len(values) > 1 && values[len(values)-1] == "" {
+				values = values[:len(values)-1]
+			}
+			return strings.Join(values, "/")
+		}
+	}
+	return ""
+}
+
+// SetText replaces the text frame id with value, an empty value removes the frame
+func (t *ID3) SetText(id, value string) {
+	frames := t.Frames[:0]
+	for _, f := range t.Frames {
+		if f.ID != id {
+			frames = append(frames, f)
+		}
+	}
+	t.Frames = frames
+	if value != "" {
+		t.Frames = append(t.Frames, ID3Frame{ID: id, Data: t.encode(value)})
+	}
+}
+
+// Pictures returns the APIC frames
+func (t *ID3) Pictures() []ID3Picture {
+	var pics []ID3Picture
+	for _, f := range t.Frames {
+		if f.ID != "APIC" || len(f.Data) < 2 {
+			continue
+		}
+		enc, b := f.Data[0], f.Data[1:]
+		i := bytes.IndexByte(b, 0)
+		if i < 0 || i+1 >= len(b) {
+			continue
+		}
+		pic := ID3Picture{MIMEType: string(b[:i]), Type: b[i+1]}
+		desc, data := id3Split(enc, b[i+2:])
+		pic.Description = id3Decode(enc, desc)
+		pic.Data = data
+		pics = append(pics, pic)
+	}
+	return pics
+}
+
+// AddPicture appends an APIC frame for pic
+func (t *ID3) AddPicture(pic ID3Picture) {
+	var b bytes.Buffer
+	desc := t.encode(pic.Description)
+	b.WriteByte(desc[0])
+	b.WriteString(pic.MIMEType)
+	b.WriteByte(0)
+	b.WriteByte(pic.Type)
+	b.Write(desc[1:])
+	b.WriteByte(0)
+	if desc[0] == id3UTF16 {
+		b.WriteByte(0)
+	}
+	b.Write(pic.Data)
+	t.Frames = append(t.Frames, ID3Frame{ID: "APIC", Data: b.Bytes()})
+}
+
+// encode returns the encoding byte and the text, UTF-8 for version 4 and UTF-16 for version 3
+func (t *ID3) encode(s string) []byte {
+	if t.Version == 3 {
+		b := []byte{id3UTF16, 0xff, 0xfe}
+		for _, u := range utf16.Encode([]rune(s)) {
+			b = append(b, byte(u), byte(u>>8))
+		}
+		return b
+	}
+	return append([]byte{id3UTF8}, s...)
+}
+
+// parseChunkID3 reads an id3 chunk of chunkSize bytes, broken tags and truncated chunks are ignored
+func (wav *Reader) parseChunkID3(chunkSize uint32) error {
+	pos, err := wav.input.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return err
+	}
+	if chunkSize < 10 || int64(chunkSize) > wav.size-pos {
+		return nil
+	}
+
+	b := make([]byte, chunkSize)
+	if _, err = io.ReadFull(wav.input, b); err != nil {
+		return err
+	}
+
+	wav.id3 = parseID3(b)
+	return nil
+}
+
+// parseID3 decodes an ID3v2 tag, it returns nil for other versions or a broken header
+func parseID3(b []byte) *ID3 {
+	if len(b) < 10 || string(b[:3]) != "ID3" || (b[3] != 3 && b[3] != 4) {
+		return nil
+	}
+	version, flags := b[3], b[5]
+	size := syncsafe(b[6:10])
+	b = b[10:]
+	if uint64(size) > uint64(len(b)) {
+		return nil
+	}
+	b = b[:size]
+
+	unsync := flags&0x80 != 0
+	if unsync && version == 3 {
+		b = id3Resync(b)
+		unsync = false
+	}
+
+	if flags&0x40 != 0 {
+		if len(b) < 4 {
+			return nil
+		}
+		ext := binary.BigEndian.Uint32(b)
+		if version == 3 {
+			ext += 4
+		} else {
+			ext = syncsafe(b)
+		}
+		if uint64(ext) > uint64(len(b)) {
+			return nil
+		}
+		b = b[ext:]
+	}
+
+	t := &ID3{Version: version}
+	for len(b) >= 10 && b[0] != 0 {
+		id := string(b[:4])
+		size := binary.BigEndian.Uint32(b[4:])
+		if version == 4 {
+			size = syncsafe(b[4:])
+		}
+		format := b[9]
+		b = b[10:]
+		if uint64(size) > uint64(len(b)) {
+			break
+		}
+		data := b[:size]
+		b = b[size:]
+
+		if version == 3 {
+			// compression or encryption
+			if format&0xc0 != 0 {
+				continue
+			}
+		} else {
+			if format&0x0c != 0 {
+				continue
+			}
+			if unsync || format&0x02 != 0 {
+				data = id3Resync(data)
+			}
+			// data length indicator
+			if format&0x01 != 0 {
+				if len(data) < 4 {
+					continue
+				}
+				data = data[4:]
+			}
+		}
+		t.Frames = append(t.Frames, ID3Frame{ID: id, Data: append([]byte(nil), data...)})
+	}
+	return t
+}
+
+// chunk returns the id3 chunk, the tag is written without unsynchronisation or padding
+func (t *ID3) chunk(order binary.ByteOrder) []byte {
+	version := t.Version
+	if version == 0 {
+		version = 4
+	}
+
+	var frames bytes.Buffer
+	for _, f := range t.Frames {
+		var id [4]byte
+		copy(id[:], f.ID+"    ")
+		frames.Write(id[:])
+		if version == 4 {
+			frames.Write(putSyncsafe(uint32(len(f.Data))))
+		} else {
+			binary.Write(&frames, binary.BigEndian, uint32(len(f.Data)))
+		}
+		frames.Write([]byte{0, 0})
+		frames.Write(f.Data)
+	}
+
+	var body bytes.Buffer
+	body.WriteString("ID3")
+	body.Write([]byte{version, 0, 0})
+	body.Write(putSyncsafe(uint32(frames.Len())))
+	body.Write(frames.Bytes())
+
+	var b bytes.Buffer
+	writeChunk(&b, order, tokenID3, body.Bytes())
+	return b.Bytes()
+}
+
+// syncsafe decodes four bytes with seven bits each
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7f)<<21 | uint32(b[1]&0x7f)<<14 | uint32(b[2]&0x7f)<<7 | uint32(b[3]&0x7f)
+}
+
+func putSyncsafe(v uint32) []byte {
+	return []byte{byte(v>>21) & 0x7f, byte(v>>14) & 0x7f, byte(v>>7) & 0x7f, byte(v) & 0x7f}
+}
+
+// id3Resync removes the zero bytes inserted after 0xff by unsynchronisation
+func id3Resync(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for i := 0; i < len(b); i++ {
+		out = append(out, b[i])
+		if b[i] == 0xff && i+1 < len(b) && b[i+1] == 0 {
+			i++
+		}
+	}
+	return out
+}
+
+// id3Split cuts b after the first terminator of the encoding enc
+func id3Split(enc byte, b []byte) (text, rest []byte) {
+	if enc == id3UTF16 || enc == id3UTF16BE {
+		for i := 0; i+1 < len(b); i += 2 {
+			if b[i] == 0 && b[i+1] == 0 {
+				return b[:i], b[i+2:]
+			}
+		}
+		return b, nil
+	}
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		return b[:i], b[i+1:]
+	}
+	return b, nil
+}
+
+// id3Decode returns the text b in the encoding enc as UTF-8, terminators become zero runes
+func id3Decode(enc byte, b []byte) string {
+	switch enc {
+	case id3Latin1:
+		r := make([]rune, len(b))
+		for i, c := range b {
+			r[i] = rune(c)
+		}
+		return string(r)
+	case id3UTF16, id3UTF16BE:
+		bigEndian := enc == id3UTF16BE
+		u := make([]uint16, 0, len(b)/2)
+		for i := 0; i+1 < len(b); i += 2 {
+			v := uint16(b[i])<<8 | uint16(b[i+1])
+			if !bigEndian {
+				v = v>>8 | v<<8
+			}
+			// every value of UTF-16 starts with a byte order mark
+			switch v {
+			case 0xfeff:
+				continue
+			case 0xfffe:
+				bigEndian = !bigEndian
+				continue
+			}
+			u = append(u, v)
+		}
+		return string(utf16.Decode(u))
+	default:
+		return string(b)
+	}
+}
@@ -0,0 +1,146 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+// id3v23 returns a version 3 tag with the frames, sizes are not syncsafe
+func id3v23(flags byte, frames ...ID3Frame) []byte {
+	var body bytes.Buffer
+	for _, f := range frames {
+		body.WriteString(f.ID)
+		binary.Write(&body, binary.BigEndian, uint32(len(f.Data)))
+		body.Write([]byte{0, 0})
+		body.Write(f.Data)
+	}
+	// padding
+	body.Write(make([]byte, 6))
+
+	tag := []byte{'I', 'D', '3', 3, 0, flags}
+	tag = append(tag, putSyncsafe(uint32(body.Len()))...)
+	return append(tag, body.Bytes()...)
+}
+
+func TestParseID3_v23(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	apic := []byte{0}
+	apic = append(apic, "image/png\x00"...)
+	apic = append(apic, 3)
+	apic = append(apic, "cover\x00"...)
+	apic = append(apic, 0x89, 'P', 'N', 'G')
+
+	tag := id3v23(0,
+		// UTF-16 with BOM, two values
+		ID3Frame{"TPE1", []byte{1, 0xff, 0xfe, 'A', 0, 0, 0, 0xff, 0xfe, 'B', 0, 0, 0}},
+		ID3Frame{"TIT2", []byte("\x00Caf\xe9")},
+		ID3Frame{"TRCK", []byte("\x033/12")},
+		ID3Frame{"APIC", apic},
+	)
+	var c bytes.Buffer
+	writeChunk(&c, binary.LittleEndian, tokenID3, tag)
+	buf := infoWave(c.Bytes())
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	id3 := rd.GetID3()
+	is.NotNil(id3)
+	is.Equal(byte(3), id3.Version)
+	is.Equal(4, len(id3.Frames))
+	is.Equal("A/B", id3.Text("TPE1"))
+	is.Equal("Café", id3.Text("TIT2"))
+	is.Equal("3/12", id3.Text("TRCK"))
+	is.Equal("", id3.Text("TALB"))
+	is.Equal([]ID3Picture{{
+		MIMEType:    "image/png",
+		Type:        3,
+		Description: "cover",
+		Data:        []byte{0x89, 'P', 'N', 'G'},
+	}}, id3.Pictures())
+	is.Equal(id3, rd.GetFile().ID3)
+
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(1), s)
+}
+
+func TestParseID3_unsync(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	// the frame size counts the bytes after removing the inserted zero
+	tag := []byte{'I', 'D', '3', 3, 0, 0x80, 0, 0, 0, 15}
+	tag = append(tag, 'P', 'R', 'I', 'V', 0, 0, 0, 3, 0, 0, 0xff, 0x00, 0xe0, 1, 0)
+	id3 := parseID3(tag)
+	is.NotNil(id3)
+	is.Equal([]byte{0xff, 0xe0, 1}, id3.Frames[0].Data)
+
+	// version 2 tags are not supported
+	tag[3] = 2
+	is.Nil(parseID3(tag))
+}
+
+func TestParseID3_truncated(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	tag := id3v23(0, ID3Frame{ID: "TIT2", Data: []byte("\x00Title")})
+	c := append([]byte("id3 \x40\x00\x00\x00"), tag...)
+	buf := infoWave(c)
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Nil(rd.GetID3())
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(1), s)
+}
+
+func TestWriteReadID3(t *testing.T) {
+	for _, version := range []byte{3, 4} {
+		is := is.New(t)
+
+		f, err := ioutil.TempFile("", "wavPkgtest")
+		is.NoErr(err)
+		defer os.Remove(f.Name())
+
+		id3 := &ID3{Version: version}
+		id3.SetText("TIT2", "Überschrift")
+		id3.SetText("TPE1", "Someone")
+		id3.SetText("TPE1", "Someone else")
+		id3.AddPicture(ID3Picture{MIMEType: "image/jpeg", Type: 3, Description: "Hülle", Data: []byte{0xff, 0xd8, 0xff}})
+
+		meta := File{
+			Channels:        1,
+			SampleRate:      44100,
+			SignificantBits: 16,
+			ID3:             id3,
+		}
+		wr, err := meta.NewWriter(f)
+		is.NoErr(err)
+		is.NoErr(wr.WriteInt32(7))
+		is.NoErr(wr.Close())
+
+		buf, err := ioutil.ReadFile(f.Name())
+		is.NoErr(err)
+		rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+		is.NoErr(err)
+
+		got := rd.GetID3()
+		is.Equal(id3, got)
+		is.Equal("Überschrift", got.Text("TIT2"))
+		is.Equal("Someone else", got.Text("TPE1"))
+		is.Equal("Hülle", got.Pictures()[0].Description)
+		is.Equal([]byte{0xff, 0xd8, 0xff}, got.Pictures()[0].Data)
+
+		s, err := rd.ReadSample()
+		is.NoErr(err)
+		is.Equal(int32(7), s)
+	}
+}
@@ -0,0 +1,158 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// Info is the metadata of a LIST/INFO chunk.
+// The values are stored as they are in the file, usually ASCII or Latin-1.
+type Info struct {
+	Title        string // INAM
+	Artist       string // IART
+	Album        string // IPRD
+	Comment      string // ICMT
+	CreationDate string // ICRD
+	Software     string // ISFT
+	Genre        string // IGNR
+	Copyright    string // ICOP
+	Engineer     string // IENG
+	Technician   string // ITCH
+	Subject      string // ISBJ
+	Keywords     string // IKEY
+	Source       string // ISRC
+	TrackNumber  string // ITRK
+
+	// Other holds the subchunks without a field above in the order of the file
+	Other []InfoEntry
+}
+
+// InfoEntry is a LIST/INFO subchunk, the ID has four characters
+type InfoEntry struct {
+	ID    string
+	Value string
+}
+
+// infoFields maps the subchunk IDs to the fields of Info, in the order they are written
+var infoFields = []struct {
+	id    [4]byte
+	field func(*Info) *string
+}{
+	{[4]byte{'I', 'N', 'A', 'M'}, func(i *Info) *string { return &i.Title }},
+	{[4]byte{'I', 'A', 'R', 'T'}, func(i *Info) *string { return &i.Artist }},
+	{[4]byte{'I', 'P', 'R', 'D'}, func(i *Info) *string { return &i.Album }},
+	{[4]byte{'I', 'C', 'M', 'T'}, func(i *Info) *string { return &i.Comment }},
+	{[4]byte{'I', 'C', 'R', 'D'}, func(i *Info) *string { return &i.CreationDate }},
+	{[4]byte{'I', 'S', 'F', 'T'}, func(i *Info) *string { return &i.Software }},
+	{[4]byte{'I', 'G', 'N', 'R'}, func(i *Info) *string { return &i.Genre }},
+	{[4]byte{'I', 'C', 'O', 'P'}, func(i *Info) *string { return &i.Copyright }},
+	{[4]byte{'I', 'E', 'N', 'G'}, func(i *Info) *string { return &i.Engineer }},
+	{[4]byte{'I', 'T', 'C', 'H'}, func(i *Info) *string { return &i.Technician }},
+	{[4]byte{'I', 'S', 'B', 'J'}, func(i *Info) *string { return &i.Subject }},
+	{[4]byte{'I', 'K', 'E', 'Y'}, func(i *Info) *string { return &i.Keywords }},
+	{[4]byte{'I', 'S', 'R', 'C'}, func(i *Info) *string { return &i.Source }},
+	{[4]byte{'I', 'T', 'R', 'K'}, func(i *Info) *string { return &i.TrackNumber }},
+}
+
+// GetInfo returns the LIST/INFO metadata, nil if the file has none
+func (wav *Reader) GetInfo() *Info {
+	return wav.info
+}
+
+// parseChunkList reads a LIST chunk of chunkSize bytes.
+// Broken lists are ignored, they don't keep the samples from being read.
+func (wav *Reader) parseChunkList(chunkSize uint32) error {
+	pos, err := wav.input.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return err
+	}
+	if chunkSize < 4 || int64(chunkSize) > wav.size-pos {
+		return nil
+	}
+
+	body := make([]byte, chunkSize)
+	if _, err = io.ReadFull(wav.input, body); err != nil {
+		return err
+	}
+
+	var listType [4]byte
+	copy(listType[:], body)
+	switch listType {
+	case tokenInfo:
+		wav.info = parseInfo(body[4:], wav.order)
+	case tokenAdtl:
+		wav.parseAdtl(body[4:])
+	}
+
+	return nil
+}
+
+// parseInfo decodes the subchunks of a LIST/INFO chunk
+func parseInfo(b []byte, order binary.ByteOrder) *Info {
+	info := new(Info)
+	for len(b) >= 8 {
+		var id [4]byte
+		copy(id[:], b)
+		size := order.Uint32(b[4:])
+		b = b[8:]
+		if uint64(size) > uint64(len(b)) {
+			break
+		}
+
+		value := string(bytes.TrimRight(b[:size], "\x00"))
+		b = b[size:]
+		if size&1 == 1 && len(b) > 0 {
+			b = b[1:]
+		}
+
+		known := false
+		for _, f := range infoFields {
+			if f.id == id {
+				*f.field(info) = value
+				known = true
+				break
+			}
+		}
+		if !known {
+			info.Other = append(info.Other, InfoEntry{ID: string(id[:]), Value: value})
+		}
+	}
+	return info
+}
+
+// chunk returns the LIST/INFO chunk for info, the values are written as zero terminated strings
+func (info *Info) chunk(order binary.ByteOrder) []byte {
+	var b bytes.Buffer
+	b.Write(tokenInfo[:])
+
+	for _, f := range infoFields {
+		if v := *f.field(info); v != "" {
+			writeInfoEntry(&b, order, f.id, v)
+		}
+	}
+	for _, e := range info.Other {
+		var id [4]byte
+		copy(id[:], e.ID+"    ")
+		writeInfoEntry(&b, order, id, e.Value)
+	}
+
+	var c bytes.Buffer
+	writeChunk(&c, order, tokenList, b.Bytes())
+	return c.Bytes()
+}
+
+func writeInfoEntry(b *bytes.Buffer, order binary.ByteOrder, id [4]byte, value string) {
+	writeChunk(b, order, id, append([]byte(value), 0))
+}
+
+// writeChunk appends a chunk with body to b, followed by a pad byte if the size is odd
+func writeChunk(b *bytes.Buffer, order binary.ByteOrder, id [4]byte, body []byte) {
+	b.Write(id[:])
+	binary.Write(b, order, uint32(len(body)))
+	b.Write(body)
+	if len(body)&1 == 1 {
+		b.WriteByte(0)
+	}
+}
@@ -0,0 +1,120 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+// infoWave returns a mono 16 bit file with the chunks after the one sample data chunk
+func infoWave(chunks ...[]byte) []byte {
+	var body bytes.Buffer
+	body.Write(wave)
+	body.Write(fmt20)
+	body.Write(testRiffChunkFmt[:20])
+	writeChunk(&body, binary.LittleEndian, tokenData, []byte{0x01, 0x00})
+	for _, c := range chunks {
+		body.Write(c)
+	}
+
+	var b bytes.Buffer
+	b.Write(riff)
+	binary.Write(&b, binary.LittleEndian, uint32(body.Len()))
+	b.Write(body.Bytes())
+	return b.Bytes()
+}
+
+func TestParseInfo(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var list bytes.Buffer
+	list.Write(tokenInfo[:])
+	writeChunk(&list, binary.LittleEndian, [4]byte{'I', 'N', 'A', 'M'}, []byte("Song\x00"))
+	writeChunk(&list, binary.LittleEndian, [4]byte{'I', 'A', 'R', 'T'}, []byte("Band"))
+	writeChunk(&list, binary.LittleEndian, [4]byte{'I', 'X', 'Y', 'Z'}, []byte("odd\x00\x00"))
+	writeChunk(&list, binary.LittleEndian, [4]byte{'I', 'C', 'R', 'D'}, []byte("2016-01-02\x00"))
+
+	var c bytes.Buffer
+	writeChunk(&c, binary.LittleEndian, tokenList, list.Bytes())
+	buf := infoWave(c.Bytes())
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+
+	info := rd.GetInfo()
+	is.NotNil(info)
+	is.Equal("Song", info.Title)
+	is.Equal("Band", info.Artist)
+	is.Equal("2016-01-02", info.CreationDate)
+	is.Equal([]InfoEntry{{"IXYZ", "odd"}}, info.Other)
+	is.Equal(info, rd.GetFile().Info)
+	is.False(rd.GetFile().Canonical)
+
+	// the samples are read after the metadata
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(1), s)
+}
+
+func TestParseInfo_broken(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	// a list larger than the file is skipped
+	buf := infoWave([]byte("LIST\xff\x00\x00\x00INFOINAM"))
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Nil(rd.GetInfo())
+
+	// as is a subchunk larger than the list
+	var c bytes.Buffer
+	writeChunk(&c, binary.LittleEndian, tokenList, []byte("INFOINAM\x10\x00\x00\x00ab"))
+	buf = infoWave(c.Bytes())
+	rd, err = NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(&Info{}, rd.GetInfo())
+}
+
+func TestWriteReadInfo(t *testing.T) {
+	is := is.New(t)
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+	defer os.Remove(f.Name())
+
+	info := &Info{
+		Title:    "A Title",
+		Artist:   "Artist",
+		Comment:  "odd",
+		Software: "github.com/cryptix/wav",
+		Genre:    "Noise",
+		Other:    []InfoEntry{{"IMED", "tape"}},
+	}
+	meta := File{
+		Channels:        1,
+		SampleRate:      8000,
+		SignificantBits: 16,
+		Info:            info,
+	}
+
+	wr, err := meta.NewWriter(f)
+	is.NoErr(err)
+	is.NoErr(wr.WriteInt32(1000))
+	is.NoErr(wr.Close())
+
+	buf, err := ioutil.ReadFile(f.Name())
+	is.NoErr(err)
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(info, rd.GetInfo())
+
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(1000), s)
+}
@@ -0,0 +1,215 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"io"
+	"os"
+)
+
+var tokenIXML = [4]byte{'i', 'X', 'M', 'L'}
+
+// IXML holds the common elements of an iXML document, as written by field recorders.
+// Elements not listed here are only available from Reader.GetIXMLRaw,
+// they are kept when a changed document of a file is written.
+type IXML struct {
+	XMLName   xml.Name       `xml:"BWFXML"`
+	Version   string         `xml:"IXML_VERSION,omitempty"`
+	Project   string         `xml:"PROJECT,omitempty"`
+	Scene     string         `xml:"SCENE,omitempty"`
+	Take      string         `xml:"TAKE,omitempty"`
+	Tape      string         `xml:"TAPE,omitempty"`
+	Circled   string         `xml:"CIRCLED,omitempty"`
+	Note      string         `xml:"NOTE,omitempty"`
+	Speed     *IXMLSpeed     `xml:"SPEED,omitempty"`
+	TrackList *IXMLTrackList `xml:"TRACK_LIST,omitempty"`
+}
+
+// IXMLSpeed is the SPEED element with the timecode and sample rates
+type IXMLSpeed struct {
+	Note                string `xml:"NOTE,omitempty"`
+	MasterSpeed         string `xml:"MASTER_SPEED,omitempty"`
+	CurrentSpeed        string `xml:"CURRENT_SPEED,omitempty"`
+	TimecodeRate        string `xml:"TIMECODE_RATE,omitempty"`
+	TimecodeFlag        string `xml:"TIMECODE_FLAG,omitempty"`
+	FileSampleRate      string `xml:"FILE_SAMPLE_RATE,omitempty"`
+	AudioBitDepth       string `xml:"AUDIO_BIT_DEPTH,omitempty"`
+	DigitizerSampleRate string `xml:"DIGITIZER_SAMPLE_RATE,omitempty"`
+	TimestampSampleRate string `xml:"TIMESTAMP_SAMPLE_RATE,omitempty"`
+
+	TimestampSamplesSinceMidnightHi string `xml:"TIMESTAMP_SAMPLES_SINCE_MIDNIGHT_HI,omitempty"`
+	TimestampSamplesSinceMidnightLo string `xml:"TIMESTAMP_SAMPLES_SINCE_MIDNIGHT_LO,omitempty"`
+}
+
+// IXMLTrackList names the channels of the file
+type IXMLTrackList struct {
+	TrackCount int         `xml:"TRACK_COUNT"`
+	Tracks     []IXMLTrack `xml:"TRACK"`
+}
+
+// IXMLTrack describes one channel, the indexes start at 1
+type IXMLTrack struct {
+	ChannelIndex    int    `xml:"CHANNEL_INDEX"`
+	InterleaveIndex int    `xml:"INTERLEAVE_INDEX"`
+	Name            string `xml:"NAME"`
+	Function        string `xml:"FUNCTION,omitempty"`
+}
+
+// GetIXML returns the parsed iXML document, nil if the file has none or it is no valid XML
+func (wav *Reader) GetIXML() *IXML {
+	return wav.ixml
+}
+
+// GetIXMLRaw returns the iXML document as stored in the file, nil if there is none
+func (wav *Reader) GetIXMLRaw() []byte {
+	return wav.ixmlRaw
+}
+
+// parseChunkIXML reads an iXML chunk of chunkSize bytes, truncated chunks are ignored
+func (wav *Reader) parseChunkIXML(chunkSize uint32) error {
+	pos, err := wav.input.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return err
+	}
+	if int64(chunkSize) > wav.size-pos {
+		return nil
+	}
+
+	raw := make([]byte, chunkSize)
+	if _, err = io.ReadFull(wav.input, raw); err != nil {
+		return err
+	}
+
+	// some writers pad the document with zeros
+	wav.ixmlRaw = bytes.TrimRight(raw, "\x00")
+
+	doc := new(IXML)
+	if err := xml.Unmarshal(wav.ixmlRaw, doc); err == nil {
+		wav.ixml = doc
+	}
+	return nil
+}
+
+// chunk returns the iXML chunk. If raw is the document orig was read from, only the
+// elements of the changed fields are replaced and the unknown elements of raw are kept.
+func (doc *IXML) chunk(order binary.ByteOrder, orig *IXML, raw []byte) []byte {
+	body := doc.marshal()
+	if orig != nil && raw != nil {
+		if merged, err := ixmlMerge(raw, orig.marshal(), body); err == nil {
+			body = merged
+		}
+	}
+
+	var b bytes.Buffer
+	writeChunk(&b, order, tokenIXML, body)
+	return b.Bytes()
+}
+
+func (doc *IXML) marshal() []byte {
+	var body bytes.Buffer
+	body.WriteString(xml.Header)
+	enc := xml.NewEncoder(&body)
+	enc.Indent("", "\t")
+	enc.Encode(doc)
+	body.WriteByte('\n')
+	return body.Bytes()
+}
+
+// ixmlElement is a child of the root element of an iXML document
+type ixmlElement struct {
+	name       string
+	start, end int64
+}
+
+// ixmlElements returns the children of the root element of the document b
+// and the offset of the closing tag of the root
+func ixmlElements(b []byte) ([]ixmlElement, int64, error) {
+	d := xml.NewDecoder(bytes.NewReader(b))
+	var elems []ixmlElement
+	depth := 0
+	for {
+		start := d.InputOffset()
+		tok, err := d.Token()
+		if err != nil {
+			return nil, 0, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if depth == 1 {
+				if err := d.Skip(); err != nil {
+					return nil, 0, err
+				}
+				elems = append(elems, ixmlElement{t.Name.Local, start, d.InputOffset()})
+				continue
+			}
+			depth++
+		case xml.EndElement:
+			depth--
+			if depth == 0 {
+				return elems, start, nil
+			}
+		}
+	}
+}
+
+// ixmlMerge replaces the elements of raw which differ between the documents old and cur
+// by those of cur. Elements missing in raw are added at the end of the root, elements
+// missing in cur are removed.
+func ixmlMerge(raw, old, cur []byte) ([]byte, error) {
+	rawElems, rawEnd, err := ixmlElements(raw)
+	if err != nil {
+		return nil, err
+	}
+	oldElems, _, err := ixmlElements(old)
+	if err != nil {
+		return nil, err
+	}
+	curElems, _, err := ixmlElements(cur)
+	if err != nil {
+		return nil, err
+	}
+
+	// the bytes of the element name in the document b, nil if there is none
+	element := func(b []byte, elems []ixmlElement, name string) []byte {
+		for _, e := range elems {
+			if e.name == name {
+				return b[e.start:e.end]
+			}
+		}
+		return nil
+	}
+
+	changed := make(map[string]bool)
+	for _, e := range append(oldElems, curElems...) {
+		changed[e.name] = !bytes.Equal(element(old, oldElems, e.name), element(cur, curElems, e.name))
+	}
+
+	var b bytes.Buffer
+	pos := int64(0)
+	for _, e := range rawElems {
+		if changed[e.name] {
+			elem := element(cur, curElems, e.name)
+			start := e.start
+			if elem == nil {
+				// the indentation of removed elements goes as well
+				start = pos + int64(len(bytes.TrimRight(raw[pos:e.start], " \t\r\n")))
+			}
+			b.Write(raw[pos:start])
+			b.Write(elem)
+			pos = e.end
+			// a later element of the same name is not replaced again
+			changed[e.name] = false
+		}
+	}
+	b.Write(raw[pos:rawEnd])
+	for _, e := range curElems {
+		if changed[e.name] && element(raw, rawElems, e.name) == nil {
+			b.WriteByte('\t')
+			b.Write(cur[e.start:e.end])
+			b.WriteByte('\n')
+		}
+	}
+	b.Write(raw[rawEnd:])
+	return b.Bytes(), nil
+}
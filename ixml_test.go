@@ -0,0 +1,161 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+const testIXML = `<?xml version="1.0" encoding="UTF-8"?>
+<BWFXML>
+	<IXML_VERSION>1.61</IXML_VERSION>
+	<PROJECT>Feature</PROJECT>
+	<SCENE>12A</SCENE>
+	<TAKE>3</TAKE>
+	<UBITS>00000000</UBITS>
+	<SPEED>
+		<MASTER_SPEED>24/1</MASTER_SPEED>
+		<TIMECODE_RATE>24/1</TIMECODE_RATE>
+		<TIMECODE_FLAG>NDF</TIMECODE_FLAG>
+	</SPEED>
+	<TRACK_LIST>
+		<TRACK_COUNT>2</TRACK_COUNT>
+		<TRACK>
+			<CHANNEL_INDEX>1</CHANNEL_INDEX>
+			<INTERLEAVE_INDEX>1</INTERLEAVE_INDEX>
+			<NAME>Boom</NAME>
+		</TRACK>
+		<TRACK>
+			<CHANNEL_INDEX>2</CHANNEL_INDEX>
+			<INTERLEAVE_INDEX>2</INTERLEAVE_INDEX>
+			<NAME>Lav 1</NAME>
+		</TRACK>
+	</TRACK_LIST>
+</BWFXML>
+`
+
+func TestParseIXML(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var c bytes.Buffer
+	writeChunk(&c, binary.LittleEndian, tokenIXML, []byte(testIXML+"\x00\x00\x00"))
+	buf := infoWave(c.Bytes())
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(testIXML, string(rd.GetIXMLRaw()))
+
+	doc := rd.GetIXML()
+	is.NotNil(doc)
+	is.Equal("Feature", doc.Project)
+	is.Equal("12A", doc.Scene)
+	is.Equal("3", doc.Take)
+	is.Equal("24/1", doc.Speed.TimecodeRate)
+	is.Equal(2, doc.TrackList.TrackCount)
+	is.Equal(IXMLTrack{ChannelIndex: 2, InterleaveIndex: 2, Name: "Lav 1"}, doc.TrackList.Tracks[1])
+	is.Equal(doc, rd.GetFile().IXML)
+
+	// invalid documents are still available raw
+	c.Reset()
+	writeChunk(&c, binary.LittleEndian, tokenIXML, []byte("<BWFXML>"))
+	buf = infoWave(c.Bytes())
+	rd, err = NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Nil(rd.GetIXML())
+	is.Equal("<BWFXML>", string(rd.GetIXMLRaw()))
+
+	// truncated
+	buf = infoWave([]byte("iXML\x40\x00\x00\x00<BWFXML></BWFXML>"))
+	rd, err = NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Nil(rd.GetIXML())
+	is.Nil(rd.GetIXMLRaw())
+}
+
+func TestWriteReadIXML(t *testing.T) {
+	is := is.New(t)
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+	defer os.Remove(f.Name())
+
+	doc := &IXML{
+		XMLName: xml.Name{Local: "BWFXML"},
+		Version: "1.61",
+		Project: "Doc",
+		Scene:   "1",
+		Take:    "2",
+		Note:    "wind",
+		Speed:   &IXMLSpeed{TimecodeRate: "25/1", FileSampleRate: "48000"},
+		TrackList: &IXMLTrackList{
+			TrackCount: 1,
+			Tracks:     []IXMLTrack{{ChannelIndex: 1, InterleaveIndex: 1, Name: "Mix"}},
+		},
+	}
+
+	meta := File{
+		Channels:        1,
+		SampleRate:      48000,
+		SignificantBits: 16,
+		IXML:            doc,
+	}
+	wr, err := meta.NewWriter(f)
+	is.NoErr(err)
+	is.NoErr(wr.WriteInt32(7))
+	is.NoErr(wr.Close())
+
+	buf, err := ioutil.ReadFile(f.Name())
+	is.NoErr(err)
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(doc, rd.GetIXML())
+	is.True(bytes.HasPrefix(rd.GetIXMLRaw(), []byte(xml.Header+"<BWFXML>")))
+
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(7), s)
+}
+
+func TestWriteReadIXML_unknownElements(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var c bytes.Buffer
+	writeChunk(&c, binary.LittleEndian, tokenIXML, []byte(testIXML))
+	buf := infoWave(c.Bytes())
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+
+	meta := rd.GetFile()
+	meta.IXML.Take = "4"
+	meta.IXML.Note = "false start"
+	meta.IXML.Speed = nil
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+	defer os.Remove(f.Name())
+	wr, err := meta.NewWriter(f)
+	is.NoErr(err)
+	is.NoErr(wr.WriteInt32(1))
+	is.NoErr(wr.Close())
+
+	buf, err = ioutil.ReadFile(f.Name())
+	is.NoErr(err)
+	rd, err = NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(meta.IXML, rd.GetIXML())
+
+	raw := string(rd.GetIXMLRaw())
+	is.True(strings.Contains(raw, "\t<UBITS>00000000</UBITS>\n"))
+	is.True(strings.Contains(raw, "\t<TAKE>4</TAKE>\n"))
+	is.True(strings.Contains(raw, "\t<NOTE>false start</NOTE>\n</BWFXML>"))
+	is.True(strings.Contains(raw, "\t<UBITS>00000000</UBITS>\n\t<TRACK_LIST>"))
+}
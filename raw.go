@@ -0,0 +1,156 @@
+package wav
+
+import (
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+	"os"
+	"time"
+)
+
+// rawInput lets a Reader work on a plain io.Reader.
+// Seeks are passed on if rd is an io.Seeker, otherwise only skipping forward works.
+type rawInput struct {
+	rd  io.Reader
+	pos int64
+}
+
+func (r *rawInput) Read(p []byte) (int, error) {
+	n, err := r.rd.Read(p)
+	r.pos += int64(n)
+	return n, err
+}
+
+func (r *rawInput) Seek(offset int64, whence int) (int64, error) {
+	if s, ok := r.rd.(io.Seeker); ok {
+		pos, err := s.Seek(offset, whence)
+		if err == nil {
+			r.pos = pos
+		}
+		return pos, err
+	}
+
+	switch whence {
+	case os.SEEK_SET:
+		offset -= r.pos
+	case os.SEEK_CUR:
+	default:
+		return r.pos, ErrNotSeekable
+	}
+	if offset < 0 {
+		return r.pos, ErrNotSeekable
+	}
+
+	n, err := io.CopyN(ioutil.Discard, r.rd, offset)
+	r.pos += n
+	return r.pos, err
+}
+
+// rawOutput lets a Writer work on a plain io.Writer, which is closed if it is an io.Closer
+type rawOutput struct {
+	io.Writer
+}
+
+func (rawOutput) Seek(offset int64, whence int) (int64, error) {
+	return 0, ErrNotSeekable
+}
+
+func (w rawOutput) Close() error {
+	if c, ok := w.Writer.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// NewRawReader returns a Reader for headerless samples described by file.
+// SampleRate, Channels, SignificantBits, AudioFormat, ValidBits, ByteOrder and Unsigned are used,
+// ByteOrder defaults to little endian and AudioFormat to PCM.
+// size is the number of bytes of samples, or -1 if the stream is read until EOF.
+// Reset only works on io.Seekers or before the first read.
+func (file File) NewRawReader(rd io.Reader, size int64) (wav *Reader, err error) {
+	if err = file.checkRaw(); err != nil {
+		return nil, err
+	}
+
+	wav = new(Reader)
+	wav.input = &rawInput{rd: rd}
+	wav.size = size
+	wav.order = binary.LittleEndian
+	wav.nativeOrder = binary.LittleEndian
+	if file.ByteOrder != nil {
+		wav.order = file.ByteOrder
+	}
+	wav.unsigned = file.Unsigned
+
+	wav.chunkFmt = &riffChunkFmt{
+		AudioFormat:   file.AudioFormat,
+		NumChannels:   file.Channels,
+		SampleRate:    file.SampleRate,
+		BytesPerSec:   uint32(file.Channels) * file.SampleRate * uint32(file.containerBytes()),
+		BytesPerBloc:  uint16(file.containerBytes()) * file.Channels,
+		BitsPerSample: file.SignificantBits,
+	}
+	if file.ValidBits != file.SignificantBits {
+		wav.validBits = file.ValidBits
+	}
+
+	wav.dataBlocSize = uint64(size)
+	if size < 0 {
+		wav.dataBlocSize = math.MaxUint64
+	}
+
+	if err = wav.setupSamples(); err != nil {
+		return nil, err
+	}
+
+	if size < 0 {
+		// unknown length
+		wav.numSamples = math.MaxUint64
+		wav.dataBlocSize = 0
+		wav.duration = time.Duration(0)
+	}
+
+	return wav, nil
+}
+
+// NewRawWriter returns a Writer which writes the samples without any header.
+// The samples are encoded like for NewRawReader, Close closes w if it is an io.Closer.
+func (file File) NewRawWriter(w io.Writer) (wr *Writer, err error) {
+	if err = file.checkRaw(); err != nil {
+		return nil, err
+	}
+
+	order := file.ByteOrder
+	if order == nil {
+		order = binary.LittleEndian
+	}
+
+	// there is no header to finish and no padding
+	wr = newWriter(rawOutput{w}, file, order)
+	wr.align = 1
+	wr.unsigned = file.Unsigned
+	wr.finish = func() error { return nil }
+
+	return wr, nil
+}
+
+// checkRaw validates the options for raw streams and fills in the defaults
+func (file *File) checkRaw() error {
+	if file.AudioFormat == 0 {
+		file.AudioFormat = AudioFormatPCM
+	}
+
+	switch file.AudioFormat {
+	case AudioFormatPCM:
+		if file.SignificantBits%8 != 0 || file.SignificantBits > 32 {
+			return ErrFormatNotSupported
+		}
+	case AudioFormatIEEEFloat:
+		if file.Unsigned {
+			return ErrFormatNotSupported
+		}
+	}
+
+	return file.checkWAV()
+}
@@ -0,0 +1,149 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+// onlyReader hides everything but Read, like a pipe
+type onlyReader struct {
+	io.Reader
+}
+
+func TestRawReader(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	meta := File{
+		SampleRate:      8000,
+		Channels:        2,
+		SignificantBits: 16,
+		ByteOrder:       binary.BigEndian,
+	}
+
+	buf := []byte{0x00, 0x01, 0x12, 0x34, 0x7f, 0xff, 0x00, 0x02}
+	rd, err := meta.NewRawReader(onlyReader{bytes.NewReader(buf)}, int64(len(buf)))
+	is.NoErr(err)
+
+	file := rd.GetFile()
+	is.Equal(uint64(4), file.NumberOfSamples)
+	is.Equal(uint64(len(buf)), file.SoundSize)
+	is.Equal(AudioFormatPCM, file.AudioFormat)
+	is.Equal(binary.BigEndian, file.ByteOrder)
+	is.False(file.Unsigned)
+
+	// skipping forward works without a Seeker
+	is.NoErr(rd.Reset())
+	for _, want := range []int32{1, 0x1234, 0x7fff, 2} {
+		s, err := rd.ReadSample()
+		is.NoErr(err)
+		is.Equal(want, s)
+	}
+	_, err = rd.ReadSample()
+	is.Equal(io.EOF, err)
+
+	is.Equal(ErrNotSeekable, rd.Reset())
+}
+
+func TestRawReader_unknownSize(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	meta := File{
+		SampleRate:      8000,
+		Channels:        1,
+		SignificantBits: 8,
+		Unsigned:        true,
+	}
+
+	rd, err := meta.NewRawReader(onlyReader{bytes.NewReader([]byte{1, 2, 3})}, -1)
+	is.NoErr(err)
+	is.True(rd.GetFile().Unsigned)
+
+	var n int
+	for {
+		_, err := rd.ReadRawSample()
+		if err == io.EOF {
+			break
+		}
+		is.NoErr(err)
+		n++
+	}
+	is.Equal(3, n)
+}
+
+func TestRawReader_broken(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	_, err := File{SampleRate: 8000, SignificantBits: 16}.NewRawReader(bytes.NewReader(nil), 0)
+	is.Err(err)
+
+	_, err = File{SampleRate: 8000, Channels: 1, SignificantBits: 12}.NewRawReader(bytes.NewReader(nil), 0)
+	is.Equal(ErrFormatNotSupported, err)
+}
+
+func TestRawWriter(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	meta := File{
+		SampleRate:      8000,
+		Channels:        1,
+		SignificantBits: 32,
+		AudioFormat:     AudioFormatIEEEFloat,
+		ByteOrder:       binary.BigEndian,
+	}
+
+	var b bytes.Buffer
+	wr, err := meta.NewRawWriter(&b)
+	is.NoErr(err)
+	is.NoErr(wr.WriteFloat32(0.5))
+	is.NoErr(wr.WriteFloat32(-1))
+	_, err = wr.Write([]byte{1, 2, 3})
+	is.NoErr(err)
+	is.NoErr(wr.Close())
+	is.Equal([]byte{0x3f, 0, 0, 0, 0xbf, 0x80, 0, 0, 1, 2, 3}, b.Bytes())
+}
+
+// a raw dump becomes a WAV file by copying the samples to a Writer
+func TestRawToWAV(t *testing.T) {
+	is := is.New(t)
+
+	meta := File{
+		SampleRate:      44100,
+		Channels:        1,
+		SignificantBits: 16,
+	}
+
+	buf := []byte{0x01, 0x00, 0x34, 0x12}
+	rd, err := meta.NewRawReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+	defer os.Remove(f.Name())
+
+	wr, err := rd.GetFile().NewWriter(f)
+	is.NoErr(err)
+	dumb, err := rd.GetDumbReader()
+	is.NoErr(err)
+	_, err = io.Copy(wr, dumb)
+	is.NoErr(err)
+	is.NoErr(wr.Close())
+
+	out, err := ioutil.ReadFile(f.Name())
+	is.NoErr(err)
+	rd, err = NewReader(bytes.NewReader(out), int64(len(out)))
+	is.NoErr(err)
+	is.Equal(uint64(2), rd.GetSampleCount())
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(1), s)
+}
@@ -1,9 +1,12 @@
 package wav
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
+	"math/bits"
 	"os"
 	"sort"
 	"time"
@@ -14,18 +17,57 @@ type Reader struct {
 	input io.ReadSeeker
 	size  int64
 
-	header   *riffHeader
-	chunkFmt *riffChunkFmt
+	header     *riffHeader
+	chunkFmt   *riffChunkFmt
+	extensible *riffChunkFmtExtensible
+	fmtExtra   []byte // format specific part of the fmt extension
+	validBits  uint16 // 0 if all bits are valid
+
+	// byte order of the samples and the usual one of the container
+	order       binary.ByteOrder
+	nativeOrder binary.ByteOrder
+	unsigned    bool // integer samples are offset binary
 
 	canonical      bool
 	extraChunk     bool
+	ds64           *riffChunkDS64 // only set for RF64 and BW64 files
+	ds64Table      []chunkSize64
 	firstSamplePos uint32
-	dataBlocSize   uint32
+	dataBlocSize   uint64
 	bytesPerSample uint32
 	duration       time.Duration
 
-	samplesRead uint32
-	numSamples  uint32
+	samplesRead uint64
+	numSamples  uint64
+
+	// metadata chunks
+	info *Info
+	bext *Bext
+
+	ixml    *IXML
+	ixmlRaw []byte
+
+	cues []Cue
+	adtl []adtlEntry
+
+	sampler    *Sampler
+	instrument *Instrument
+	id3        *ID3
+	acid       *Acid
+
+	chunks []Chunk
+	raw    []RawChunk
+
+	// compressed formats have a fact chunk with the number of sample frames
+	factSamples uint64
+	hasFact     bool
+
+	// block based formats are decoded one block at a time
+	decoder    blockDecoder
+	block      []byte
+	blockRead  uint64 // bytes of the data chunk consumed
+	decoded    []int16
+	decodedPos int
 }
 
 func (wav Reader) String() string {
@@ -38,6 +80,11 @@ func (wav Reader) String() string {
 	msg += fmt.Sprintf("Number of channels: %d\n", wav.chunkFmt.NumChannels)
 	msg += fmt.Sprintf("Sampling rate     : %d Hz\n", wav.chunkFmt.SampleRate)
 	msg += fmt.Sprintf("Sample size       : %d bits\n", wav.chunkFmt.BitsPerSample)
+	if wav.extensible != nil {
+		msg += fmt.Sprintf("Valid bits        : %d\n", wav.extensible.ValidBitsPerSample)
+		msg += fmt.Sprintf("Channel mask      : %#x\n", wav.extensible.ChannelMask)
+		msg += fmt.Sprintf("SubFormat         : %s\n", wav.extensible.SubFormat)
+	}
 	// calculated
 	msg += fmt.Sprintf("Number of samples : %d\n", wav.numSamples)
 	msg += fmt.Sprintf("Sound size        : %d bytes\n", wav.dataBlocSize)
@@ -47,14 +94,13 @@ func (wav Reader) String() string {
 }
 
 // NewReader returns a new WAV reader wrapper
+// Files larger than 4 GiB need to be RF64, BW64 or Wave64.
 func NewReader(rd io.ReadSeeker, size int64) (wav *Reader, err error) {
-	if size > maxSize {
-		return nil, ErrInputToLarge
-	}
-
 	wav = new(Reader)
 	wav.input = rd
 	wav.size = size
+	wav.order = binary.LittleEndian
+	wav.nativeOrder = binary.LittleEndian
 
 	err = wav.parseHeaders()
 	if err != nil {
@@ -75,115 +121,437 @@ func (wav *Reader) parseHeaders() (err error) {
 	)
 
 	// decode header
-	if err = binary.Read(wav.input, binary.LittleEndian, wav.header); err != nil {
+	if err = binary.Read(wav.input, wav.order, wav.header); err != nil {
 		return err
 	}
 
-	if wav.header.Ftype != tokenRiff {
-		return ErrNotRiff
-	}
+	switch wav.header.Ftype {
+	case tokenRifx:
+		// everything is big endian
+		wav.order = binary.BigEndian
+		wav.header.ChunkSize = bits.ReverseBytes32(wav.header.ChunkSize)
+		fallthrough
+	case tokenRiff:
+		if wav.size > maxSize {
+			return ErrInputToLarge
+		}
 
-	if wav.header.ChunkSize+8 != uint32(wav.size) {
-		return ErrIncorrectChunkSize{wav.header.ChunkSize + 8, uint32(wav.size)}
-	}
+		if int64(wav.header.ChunkSize)+8 != wav.size {
+			return ErrIncorrectChunkSize{int64(wav.header.ChunkSize) + 8, wav.size}
+		}
 
-	if wav.header.ChunkFormat != tokenWaveFormat {
-		return ErrNotWave
+		if wav.header.ChunkFormat != tokenWaveFormat {
+			return ErrNotWave
+		}
+	case tokenRF64, tokenBW64:
+		if wav.header.ChunkFormat != tokenWaveFormat {
+			return ErrNotWave
+		}
+
+		if err = wav.parseChunkDS64(); err != nil {
+			return err
+		}
+
+		if int64(wav.ds64.RiffSize)+8 != wav.size {
+			return ErrIncorrectChunkSize{int64(wav.ds64.RiffSize) + 8, wav.size}
+		}
+	case tokenW64Riff:
+		return wav.parseW64Headers()
+	default:
+		return ErrNotRiff
 	}
 
-readLoop:
+	// the chunks following the data chunk are read as well, for the metadata
+	var dataFound bool
 	for {
 		// Read next chunkID
 		err = binary.Read(wav.input, binary.BigEndian, &chunk)
-		if err == io.EOF {
+		if err == io.EOF && dataFound {
+			break
+		} else if err == io.EOF {
 			return io.ErrUnexpectedEOF
 		} else if err != nil {
 			return err
 		}
 
 		// and it's size in bytes
-		err = binary.Read(wav.input, binary.LittleEndian, &chunkSize)
+		err = binary.Read(wav.input, wav.order, &chunkSize)
 		if err == io.EOF {
 			return io.ErrUnexpectedEOF
 		} else if err != nil {
 			return err
 		}
 
+		var body int64
+		if body, err = wav.input.Seek(0, os.SEEK_CUR); err != nil {
+			return err
+		}
+		size := int64(chunkSize)
+		if chunkSize == 0xFFFFFFFF && chunk != tokenData {
+			if s, ok := wav.tableSize(chunk); ok && s <= uint64(wav.size) {
+				size = int64(s)
+			}
+		}
+
 		switch chunk {
 		case tokenChunkFmt:
-			// seek 4 bytes back because riffChunkFmt reads the chunkSize again
-			if _, err = wav.input.Seek(-4, os.SEEK_CUR); err != nil {
+			wav.canonical = chunkSize == 16 // canonical format if chunklen == 16
+			if err = wav.parseChunkFmt(chunkSize); err != nil {
 				return err
 			}
-			wav.canonical = chunkSize == 16 // canonical format if chunklen == 16
-			if err = wav.parseChunkFmt(); err != nil {
+		case tokenFact:
+			wav.extraChunk = true
+			if chunkSize < 4 {
+				return ErrBrokenChunkFact
+			}
+			var samples uint32
+			if err = binary.Read(wav.input, wav.order, &samples); err != nil {
 				return err
 			}
+			wav.hasFact = true
+			wav.factSamples = uint64(samples)
+			if wav.ds64 != nil && samples == 0xFFFFFFFF {
+				wav.factSamples = wav.ds64.SampleCount
+			}
 		case tokenData:
-			size, _ := wav.input.Seek(0, os.SEEK_CUR)
-			wav.firstSamplePos = uint32(size)
-			wav.dataBlocSize = uint32(chunkSize)
-			break readLoop
+			dataFound = true
+			wav.firstSamplePos = uint32(body)
+			wav.dataBlocSize = uint64(chunkSize)
+			if wav.ds64 != nil && chunkSize == 0xFFFFFFFF {
+				wav.dataBlocSize = wav.ds64.DataSize
+			}
+			size = int64(wav.dataBlocSize)
 		default:
-			//fmt.Fprintf(os.Stderr, "Skip unused chunk \"%s\" (%d bytes).\n", chunk, chunkSize)
 			wav.extraChunk = true
-			if _, err = wav.input.Seek(int64(chunkSize), os.SEEK_CUR); err != nil {
-				return err
+			// metadata doesn't come in chunks of 4 GiB
+			if size == int64(chunkSize) {
+				if err = wav.parseChunkMeta(chunk, chunkSize); err != nil {
+					return err
+				}
 			}
 		}
+		c := Chunk{ID: string(chunk[:]), Offset: body, Size: size}
+		wav.chunks = append(wav.chunks, c)
+
+		// everything but the format and the samples is kept for rewriting the file, truncated chunks are dropped
+		if chunk != tokenChunkFmt && chunk != tokenFact && chunk != tokenData && chunk != tokenJunk && size < 0xFFFFFFFF && body+size <= wav.size {
+			wav.raw = append(wav.raw, RawChunk{ID: c.ID, AfterData: dataFound, src: wav, chunk: c})
+		}
+
+		// chunks are word aligned
+		next := body + size + size&1
+		if dataFound && next+8 > wav.size {
+			break
+		}
+		if _, err = wav.input.Seek(next, os.SEEK_SET); err != nil {
+			return err
+		}
 	}
 
 	if wav.chunkFmt == nil {
 		return ErrBrokenChunkFmt
 	}
 
-	wav.bytesPerSample = uint32(wav.chunkFmt.BitsPerSample / 8)
+	// the labels may come before or after the cue points
+	wav.mergeCues()
 
-	if wav.bytesPerSample == 0 {
-		return ErrNoBitsPerSample
+	// move to the first sample
+	if _, err = wav.input.Seek(int64(wav.firstSamplePos), os.SEEK_SET); err != nil {
+		return err
 	}
 
-	wav.numSamples = wav.dataBlocSize / wav.bytesPerSample
-	wav.duration = time.Duration(float64(wav.numSamples)/float64(wav.chunkFmt.SampleRate)) * time.Second
+	return wav.setupSamples()
+}
 
+// parseChunkMeta reads the metadata chunks, other chunks are skipped
+func (wav *Reader) parseChunkMeta(chunk [4]byte, chunkSize uint32) error {
+	switch chunk {
+	case tokenList:
+		return wav.parseChunkList(chunkSize)
+	case tokenBext:
+		return wav.parseChunkBext(chunkSize)
+	case tokenIXML:
+		return wav.parseChunkIXML(chunkSize)
+	case tokenCue:
+		return wav.parseChunkCue(chunkSize)
+	case tokenSmpl:
+		return wav.parseChunkSmpl(chunkSize)
+	case tokenInst:
+		return wav.parseChunkInst(chunkSize)
+	case tokenID3, tokenID3Upper:
+		return wav.parseChunkID3(chunkSize)
+	case tokenAcid:
+		return wav.parseChunkAcid(chunkSize)
+	}
 	return nil
 }
 
-// parseChunkFmt
-func (wav *Reader) parseChunkFmt() (err error) {
-	wav.chunkFmt = new(riffChunkFmt)
+// setupSamples calculates the sample count and duration, once the headers are parsed
+func (wav *Reader) setupSamples() error {
+	if wav.decoder != nil {
+		// samples are decoded to 16 bit
+		wav.bytesPerSample = 2
+		wav.block = make([]byte, wav.chunkFmt.BytesPerBloc)
+
+		var frames uint64
+		if wav.hasFact {
+			frames = wav.factSamples
+		} else {
+			blockAlign := uint64(wav.chunkFmt.BytesPerBloc)
+			frames = wav.dataBlocSize / blockAlign * uint64(wav.decoder.blockFrames(int(blockAlign)))
+			frames += uint64(wav.decoder.blockFrames(int(wav.dataBlocSize % blockAlign)))
+		}
+		wav.numSamples = frames * uint64(wav.chunkFmt.NumChannels)
+	} else {
+		if wav.chunkFmt.BitsPerSample < 8 {
+			return ErrNoBitsPerSample
+		}
+
+		// samples with odd bit depths are padded to whole bytes
+		wav.bytesPerSample = (uint32(wav.chunkFmt.BitsPerSample) + 7) / 8
 
-	if err = binary.Read(wav.input, binary.LittleEndian, wav.chunkFmt); err != nil {
+		wav.numSamples = wav.dataBlocSize / uint64(wav.bytesPerSample)
+	}
+
+	if channels, rate := uint64(wav.chunkFmt.NumChannels), wav.chunkFmt.SampleRate; channels > 0 && rate > 0 {
+		frames := wav.numSamples / channels
+		wav.duration = time.Duration(float64(frames) / float64(rate) * float64(time.Second))
+	}
+
+	return nil
+}
+
+// parseChunkDS64 reads the 64 bit sizes which have to follow the RF64 header
+func (wav *Reader) parseChunkDS64() (err error) {
+	var (
+		chunk     [4]byte
+		chunkSize uint32
+	)
+
+	if err = binary.Read(wav.input, binary.BigEndian, &chunk); err != nil {
 		return err
 	}
 
-	if wav.canonical == false {
-		var extraparams uint32
-		// Get extra params size
-		if err = binary.Read(wav.input, binary.LittleEndian, &extraparams); err != nil {
+	if err = binary.Read(wav.input, wav.order, &chunkSize); err != nil {
+		return err
+	}
+
+	if chunk != tokenDS64 || chunkSize < 28 {
+		return ErrBrokenChunkDS64
+	}
+
+	wav.ds64 = new(riffChunkDS64)
+	if err = binary.Read(wav.input, wav.order, wav.ds64); err != nil {
+		return err
+	}
+	wav.chunks = append(wav.chunks, Chunk{ID: string(chunk[:]), Offset: 20, Size: int64(chunkSize)})
+
+	// the table holds the sizes of other chunks larger than 4 GiB
+	pos, err := wav.input.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return err
+	}
+	if int64(chunkSize-28) > wav.size-pos {
+		return ErrBrokenChunkDS64
+	}
+	n := (chunkSize - 28) / 12
+	if wav.ds64.TableLength < n {
+		n = wav.ds64.TableLength
+	}
+	wav.ds64Table = make([]chunkSize64, n)
+	if err = binary.Read(wav.input, wav.order, wav.ds64Table); err != nil {
+		return err
+	}
+
+	_, err = wav.input.Seek(int64(chunkSize-28-12*n), os.SEEK_CUR)
+	return err
+}
+
+// tableSize returns the size of chunk from the ds64 table, each entry is used once
+func (wav *Reader) tableSize(chunk [4]byte) (uint64, bool) {
+	for i, e := range wav.ds64Table {
+		if e.ChunkID == chunk {
+			wav.ds64Table = append(wav.ds64Table[:i], wav.ds64Table[i+1:]...)
+			return e.Size, true
+		}
+	}
+	return 0, false
+}
+
+// parseChunkFmt reads the body of a fmt chunk of chunkSize bytes
+func (wav *Reader) parseChunkFmt(chunkSize uint32) (err error) {
+	var body [16]byte
+	if _, err = io.ReadFull(wav.input, body[:]); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	wav.chunkFmt = &riffChunkFmt{
+		LengthOfHeader: chunkSize,
+		AudioFormat:    wav.order.Uint16(body[0:]),
+		NumChannels:    wav.order.Uint16(body[2:]),
+		SampleRate:     wav.order.Uint32(body[4:]),
+		BytesPerSec:    wav.order.Uint32(body[8:]),
+		BytesPerBloc:   wav.order.Uint16(body[12:]),
+		BitsPerSample:  wav.order.Uint16(body[14:]),
+	}
+
+	if wav.chunkFmt.LengthOfHeader < 16 {
+		return ErrBrokenChunkFmt
+	}
+
+	// WAVEFORMATEX appends cbSize and cbSize bytes of format specific data
+	skip := int64(wav.chunkFmt.LengthOfHeader) - 16
+	if skip >= 2 {
+		var cbSize uint16
+		if err = binary.Read(wav.input, wav.order, &cbSize); err != nil {
 			return err
 		}
-		// Skip them
-		if _, err = wav.input.Seek(int64(extraparams), os.SEEK_CUR); err != nil {
+		skip -= 2
+
+		if int64(cbSize) < skip {
+			wav.fmtExtra = make([]byte, cbSize)
+		} else {
+			wav.fmtExtra = make([]byte, skip)
+		}
+		if _, err = io.ReadFull(wav.input, wav.fmtExtra); err != nil {
+			return err
+		}
+		skip -= int64(len(wav.fmtExtra))
+
+		if wav.chunkFmt.AudioFormat == AudioFormatExtensible {
+			if len(wav.fmtExtra) < 22 {
+				return ErrBrokenChunkFmt
+			}
+
+			wav.extensible = new(riffChunkFmtExtensible)
+			if err = binary.Read(bytes.NewReader(wav.fmtExtra), wav.order, wav.extensible); err != nil {
+				return err
+			}
+			wav.fmtExtra = wav.fmtExtra[22:]
+
+			format, ok := wav.extensible.SubFormat.AudioFormat()
+			if !ok {
+				return ErrFormatNotSupported
+			}
+			wav.chunkFmt.AudioFormat = format
+
+			if wav.extensible.ValidBitsPerSample > wav.chunkFmt.BitsPerSample {
+				return ErrBrokenChunkFmt
+			}
+			if wav.extensible.ValidBitsPerSample != wav.chunkFmt.BitsPerSample {
+				wav.validBits = wav.extensible.ValidBitsPerSample
+			}
+		}
+	}
+
+	// Skip the rest
+	if skip > 0 {
+		if _, err = wav.input.Seek(skip, os.SEEK_CUR); err != nil {
 			return err
 		}
 	}
 
 	// Is audio supported ?
-	if wav.chunkFmt.AudioFormat != 1 {
+	switch wav.chunkFmt.AudioFormat {
+	case AudioFormatPCM:
+		if wav.chunkFmt.BitsPerSample > 32 {
+			return ErrFormatNotSupported
+		}
+		// only 8 bit samples are unsigned
+		wav.unsigned = wav.chunkFmt.BitsPerSample <= 8
+	case AudioFormatIEEEFloat:
+		if wav.chunkFmt.BitsPerSample != 32 && wav.chunkFmt.BitsPerSample != 64 {
+			return ErrFormatNotSupported
+		}
+	case AudioFormatALaw, AudioFormatMULaw:
+		if wav.chunkFmt.BitsPerSample != 8 {
+			return ErrFormatNotSupported
+		}
+	case AudioFormatMSADPCM, AudioFormatIMAADPCM:
+		// the block headers are always little endian
+		if wav.order != binary.LittleEndian {
+			return ErrFormatNotSupported
+		}
+		if wav.chunkFmt.AudioFormat == AudioFormatMSADPCM {
+			return wav.setupMSDecoder()
+		}
+		return wav.setupIMADecoder()
+	default:
 		return ErrFormatNotSupported
 	}
 
 	return nil
 }
 
+// setupMSDecoder validates the Microsoft ADPCM parameters.
+// The fmt extension holds the number of samples per block and the coefficient sets.
+func (wav *Reader) setupMSDecoder() error {
+	channels := int(wav.chunkFmt.NumChannels)
+	blockAlign := int(wav.chunkFmt.BytesPerBloc)
+	if wav.chunkFmt.BitsPerSample != 4 || channels == 0 || blockAlign < 7*channels || len(wav.fmtExtra) < 4 {
+		return ErrBrokenChunkFmt
+	}
+
+	dec := msDecoder{
+		channels:        channels,
+		samplesPerBlock: int(binary.LittleEndian.Uint16(wav.fmtExtra)),
+	}
+	if dec.samplesPerBlock == 0 || dec.samplesPerBlock > msSamplesPerBlock(blockAlign, channels) {
+		return ErrBrokenChunkFmt
+	}
+
+	numCoef := int(binary.LittleEndian.Uint16(wav.fmtExtra[2:]))
+	coefs := wav.fmtExtra[4:]
+	if numCoef == 0 || len(coefs) < 4*numCoef {
+		return ErrBrokenChunkFmt
+	}
+	for i := 0; i < numCoef; i++ {
+		dec.coefs = append(dec.coefs, [2]int{
+			int(int16(binary.LittleEndian.Uint16(coefs[4*i:]))),
+			int(int16(binary.LittleEndian.Uint16(coefs[4*i+2:]))),
+		})
+	}
+
+	wav.decoder = dec
+	return nil
+}
+
+// setupIMADecoder validates the IMA ADPCM parameters.
+// The fmt extension holds the number of samples per block.
+func (wav *Reader) setupIMADecoder() error {
+	channels := int(wav.chunkFmt.NumChannels)
+	blockAlign := int(wav.chunkFmt.BytesPerBloc)
+	if wav.chunkFmt.BitsPerSample != 4 || channels == 0 || blockAlign <= 4*channels || blockAlign%(4*channels) != 0 {
+		return ErrBrokenChunkFmt
+	}
+
+	dec := imaDecoder{
+		channels:        channels,
+		samplesPerBlock: imaSamplesPerBlock(blockAlign, channels),
+	}
+	if len(wav.fmtExtra) >= 2 {
+		spb := int(binary.LittleEndian.Uint16(wav.fmtExtra))
+		if spb == 0 || spb > dec.samplesPerBlock {
+			return ErrBrokenChunkFmt
+		}
+		dec.samplesPerBlock = spb
+	}
+
+	wav.decoder = dec
+	return nil
+}
+
 // GetSampleCount returns the number of samples
-func (wav *Reader) GetSampleCount() uint32 {
+func (wav *Reader) GetSampleCount() uint64 {
 	return wav.numSamples
 }
 
-// GetAudioFormat returns the audio format. A value of 1 indicates uncompressed PCM.
-// Any other value indicates a compressed format
+// GetAudioFormat returns the audio format. A value of 1 indicates uncompressed PCM,
+// 3 indicates IEEE float. Any other value indicates a compressed format.
+// For extensible files this is the format of the SubFormat GUID
 func (wav *Reader) GetAudioFormat() uint16 {
 	return wav.chunkFmt.AudioFormat
 }
@@ -208,6 +576,23 @@ func (wav *Reader) GetBytesPerSec() uint32 {
 	return wav.chunkFmt.BytesPerSec
 }
 
+// GetValidBits returns the number of valid bits per sample.
+// It is smaller than GetBitsPerSample for e.g. 20 bit samples in 24 bit containers
+func (wav *Reader) GetValidBits() uint16 {
+	if wav.validBits != 0 {
+		return wav.validBits
+	}
+	return wav.chunkFmt.BitsPerSample
+}
+
+// GetChannelMask returns the speaker positions of the channels, 0 if unknown
+func (wav *Reader) GetChannelMask() uint32 {
+	if wav.extensible == nil {
+		return 0
+	}
+	return wav.extensible.ChannelMask
+}
+
 // GetDuration returns the length of audio
 func (wav *Reader) GetDuration() time.Duration {
 	return wav.duration
@@ -215,7 +600,7 @@ func (wav *Reader) GetDuration() time.Duration {
 
 // GetFile returns File
 func (wav Reader) GetFile() File {
-	return File{
+	f := File{
 		SampleRate:      wav.chunkFmt.SampleRate,
 		Channels:        wav.chunkFmt.NumChannels,
 		SignificantBits: wav.chunkFmt.BitsPerSample,
@@ -226,6 +611,27 @@ func (wav Reader) GetFile() File {
 		Duration:        wav.duration,
 		Canonical:       wav.canonical && !wav.extraChunk,
 	}
+	if wav.extensible != nil {
+		f.ValidBits = wav.extensible.ValidBitsPerSample
+		f.ChannelMask = wav.extensible.ChannelMask
+		f.SubFormat = wav.extensible.SubFormat
+	} else {
+		f.ValidBits = wav.validBits
+	}
+	if wav.order != wav.nativeOrder {
+		f.ByteOrder = wav.order
+	}
+	f.Unsigned = wav.unsigned
+	f.Info = wav.info
+	f.Bext = wav.bext
+	f.IXML = wav.ixml
+	f.Cues = wav.cues
+	f.Sampler = wav.sampler
+	f.Instrument = wav.instrument
+	f.ID3 = wav.id3
+	f.Acid = wav.acid
+	f.Chunks = wav.raw
+	return f
 }
 
 // FirstSampleOffset in the WAV stream
@@ -238,6 +644,9 @@ func (wav *Reader) Reset() (err error) {
 	_, err = wav.input.Seek(int64(wav.firstSamplePos), os.SEEK_SET)
 	if err == nil {
 		wav.samplesRead = 0
+		wav.blockRead = 0
+		wav.decoded = wav.decoded[:0]
+		wav.decodedPos = 0
 	}
 
 	return
@@ -255,19 +664,27 @@ func (wav Reader) GetDumbReader() (r io.Reader, err error) {
 }
 
 // ReadRawSample returns the raw []byte slice
+// Samples of compressed formats are returned decoded as 16 bit little endian.
 func (wav *Reader) ReadRawSample() ([]byte, error) {
-	if wav.samplesRead > wav.numSamples {
+	if wav.samplesRead >= wav.numSamples {
 		return nil, io.EOF
 	}
 
-	buf := make([]byte, wav.bytesPerSample)
-	n, err := wav.input.Read(buf)
-	if err != nil {
-		return nil, err
+	if wav.decoder != nil {
+		s, err := wav.readDecoded()
+		if err != nil {
+			return nil, err
+		}
+		return []byte{byte(s), byte(s >> 8)}, nil
 	}
 
-	if n != int(wav.bytesPerSample) {
+	buf := make([]byte, wav.bytesPerSample)
+	// raw streams may return short reads
+	n, err := io.ReadFull(wav.input, buf)
+	if err == io.ErrUnexpectedEOF {
 		return nil, fmt.Errorf("Read %d bytes, should have read %d", n, wav.bytesPerSample)
+	} else if err != nil {
+		return nil, err
 	}
 
 	wav.samplesRead++
@@ -275,32 +692,153 @@ func (wav *Reader) ReadRawSample() ([]byte, error) {
 	return buf, nil
 }
 
-// ReadSample returns the parsed sample bytes as integers
+// ReadSample returns the parsed sample bytes as signed integers.
+// Integer samples range from -2^(n-1) to 2^(n-1)-1 for n valid bits, so a 20 bit sample in
+// a 24 bit container is shifted down and unsigned 8 bit samples are centered around zero.
+// A-law, μ-law and ADPCM samples are decoded to 16 bit linear values
 func (wav *Reader) ReadSample() (n int32, err error) {
+	if wav.chunkFmt.AudioFormat == AudioFormatIEEEFloat {
+		return 0, ErrSampleType
+	}
+
+	if wav.decoder != nil {
+		if wav.samplesRead >= wav.numSamples {
+			return 0, io.EOF
+		}
+		s, err := wav.readDecoded()
+		return int32(s), err
+	}
+
 	s, err := wav.ReadRawSample()
 	if err != nil {
 		return 0, err
 	}
 
-	switch wav.bytesPerSample {
-	case 1:
-		n = int32(s[0])
-	case 2:
-		n = int32(s[0]) + int32(s[1])<<8
-	case 3:
-		n = int32(s[0]) + int32(s[1])<<8 + int32(s[2])<<16
-	case 4:
-		n = int32(s[0]) + int32(s[1])<<8 + int32(s[2])<<16 + int32(s[3])<<24
-	default:
-		n = 0
-		err = fmt.Errorf("Unhandled bytesPerSample! b:%d", wav.bytesPerSample)
+	switch wav.chunkFmt.AudioFormat {
+	case AudioFormatALaw:
+		return int32(alawDecode[s[0]]), nil
+	case AudioFormatMULaw:
+		return int32(ulawDecode[s[0]]), nil
 	}
 
-	return
+	if len(s) > 4 {
+		return 0, fmt.Errorf("Unhandled bytesPerSample! b:%d", wav.bytesPerSample)
+	}
+
+	if wav.order == binary.BigEndian {
+		for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+			s[i], s[j] = s[j], s[i]
+		}
+	}
+
+	var u uint32
+	for i := len(s) - 1; i >= 0; i-- {
+		u = u<<8 | uint32(s[i])
+	}
+
+	bits := 8 * uint(len(s))
+	if wav.unsigned {
+		// offset binary to two's complement
+		u ^= 1 << (bits - 1)
+	}
+
+	// sign extend and drop the padding bits below the valid ones
+	return int32(u<<(32-bits)) >> (32 - uint(wav.GetValidBits())), nil
 }
 
-// ReadSampleEvery returns the parsed sample bytes as integers every X samples
+// readDecoded returns the next sample of a block based format, decoding the next block if needed
+func (wav *Reader) readDecoded() (int16, error) {
+	if wav.decodedPos >= len(wav.decoded) {
+		remaining := wav.dataBlocSize - wav.blockRead
+		if remaining == 0 {
+			return 0, io.EOF
+		}
+
+		block := wav.block
+		if remaining < uint64(len(block)) {
+			block = block[:remaining]
+		}
+
+		n, err := io.ReadFull(wav.input, block)
+		if err == io.ErrUnexpectedEOF {
+			// truncated file, decode what we got
+			block = block[:n]
+		} else if err != nil {
+			return 0, err
+		}
+		wav.blockRead += uint64(len(block))
+
+		wav.decoded, err = wav.decoder.decodeBlock(block, wav.decoded)
+		if err != nil {
+			return 0, err
+		}
+		wav.decodedPos = 0
+
+		if len(wav.decoded) == 0 {
+			return 0, io.ErrUnexpectedEOF
+		}
+	}
+
+	s := wav.decoded[wav.decodedPos]
+	wav.decodedPos++
+	wav.samplesRead++
+	return s, nil
+}
+
+// ReadFloat64 returns the next sample as float64.
+// IEEE float samples are returned as stored, integer samples are scaled to [-1, 1)
+func (wav *Reader) ReadFloat64() (float64, error) {
+	if wav.chunkFmt.AudioFormat != AudioFormatIEEEFloat {
+		n, err := wav.ReadSample()
+		if err != nil {
+			return 0, err
+		}
+		return float64(n) / float64(uint64(1)<<(wav.sampleBits()-1)), nil
+	}
+
+	s, err := wav.ReadRawSample()
+	if err != nil {
+		return 0, err
+	}
+
+	if len(s) == 8 {
+		return math.Float64frombits(wav.order.Uint64(s)), nil
+	}
+	return float64(math.Float32frombits(wav.order.Uint32(s))), nil
+}
+
+// sampleBits returns the bit depth of the values returned by ReadSample
+func (wav *Reader) sampleBits() uint16 {
+	switch wav.chunkFmt.AudioFormat {
+	case AudioFormatALaw, AudioFormatMULaw:
+		return 16
+	}
+	if wav.decoder != nil {
+		return 16
+	}
+	return wav.GetValidBits()
+}
+
+// ReadFloat32 returns the next sample as float32, see ReadFloat64
+func (wav *Reader) ReadFloat32() (float32, error) {
+	if wav.chunkFmt.AudioFormat == AudioFormatIEEEFloat && wav.bytesPerSample == 4 {
+		s, err := wav.ReadRawSample()
+		if err != nil {
+			return 0, err
+		}
+		return math.Float32frombits(wav.order.Uint32(s)), nil
+	}
+
+	f, err := wav.ReadFloat64()
+	return float32(f), err
+}
+
+// ReadSampleEvery returns the parsed sample bytes as integers every X samples.
+// Block based compressed formats can't skip samples and are not supported.
 func (wav *Reader) ReadSampleEvery(every uint32, average int) (samples []int32, err error) {
+	if wav.decoder != nil {
+		return nil, ErrFormatNotSupported
+	}
 
 	// Reset any other readers
 	err = wav.Reset()
@@ -310,7 +848,7 @@ func (wav *Reader) ReadSampleEvery(every uint32, average int) (samples []int32,
 
 	var n int32
 	var total int
-	total = int(wav.numSamples / every)
+	total = int(wav.numSamples / uint64(every))
 	for total >= 0 {
 		total = total - 1
 
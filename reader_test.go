@@ -2,9 +2,11 @@ package wav
 
 import (
 	"bytes"
+	"encoding/binary"
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/cheekybits/is"
 )
@@ -43,8 +45,13 @@ func init() {
 func TestNewReader_inputTooLarge(t *testing.T) {
 	t.Parallel()
 	is := is.New(t)
+	// plain RIFF files can't be larger than 4 GiB
+	var b bytes.Buffer
+	b.Write(riff)
+	b.Write([]byte{0xff, 0xff, 0xff, 0xff}) // chunkSize
+	b.Write(wave)
 	_, err := NewReader(
-		bytes.NewReader([]byte{}),
+		bytes.NewReader(b.Bytes()),
 		99999999999999999)
 	is.Equal(err, ErrInputToLarge)
 }
@@ -63,7 +70,7 @@ func TestParseHeaders_complete(t *testing.T) {
 	wavFile := bytes.NewReader(b.Bytes())
 	wavReader, err := NewReader(wavFile, int64(b.Len()))
 	is.NoErr(err)
-	is.Equal(uint32(0), wavReader.GetSampleCount())
+	is.Equal(uint64(0), wavReader.GetSampleCount())
 	is.Equal(File{
 		SampleRate:      44100,
 		Channels:        1,
@@ -193,7 +200,7 @@ func TestReadSample_Raw(t *testing.T) {
 	wavFile := bytes.NewReader(wavWithOneSample)
 	wavReader, err := NewReader(wavFile, int64(len(wavWithOneSample)))
 	is.NoErr(err)
-	is.Equal(uint32(1), wavReader.GetSampleCount())
+	is.Equal(uint64(1), wavReader.GetSampleCount())
 	rawSample, err := wavReader.ReadRawSample()
 	is.NoErr(err)
 	is.Equal([]byte{1, 1}, rawSample)
@@ -205,7 +212,7 @@ func TestReadSample(t *testing.T) {
 	wavFile := bytes.NewReader(wavWithOneSample)
 	wavReader, err := NewReader(wavFile, int64(len(wavWithOneSample)))
 	is.NoErr(err)
-	is.Equal(uint32(1), wavReader.GetSampleCount())
+	is.Equal(uint64(1), wavReader.GetSampleCount())
 	sample, err := wavReader.ReadSample()
 	is.NoErr(err)
 	is.Equal(257, sample)
@@ -258,3 +265,282 @@ func TestReadFuzzed_panic2(t *testing.T) {
 	is.Err(err)
 	is.Equal(ErrBrokenChunkFmt, err)
 }
+
+// BitsPerSample+7 overflowed and the sample size became zero
+func TestReadFuzzed_bitsOverflow(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	wavFile := strings.NewReader("RIFF$\x00\x00\x00WAVEfmt \x10\x00\x00\x00\x01\x00\x01\x00D\xac\x00\x00\x88X\x01\x00\x02\x00\xff\xffdata\x00\x00\x00\x00")
+	_, err := NewReader(wavFile, int64(wavFile.Len()))
+	is.Equal(ErrFormatNotSupported, err)
+}
+
+func TestParseHeaders_waveFormatEx(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	// 18 byte fmt chunk with cbSize = 0, followed by a sample
+	var b bytes.Buffer
+	b.Write(riff)
+	b.Write([]byte{0x28, 0x00, 0x00, 0x00}) // chunkSize
+	b.Write(wave)
+	b.Write(fmt20)
+	b.Write([]byte{0x12, 0x00, 0x00, 0x00}) // LengthOfHeader
+	b.Write(testRiffChunkFmt[4:20])         // PCM, mono, 44100, 16 bit
+	b.Write([]byte{0x00, 0x00})             // cbSize
+	b.Write([]byte{0x64, 0x61, 0x74, 0x61}) // "data"
+	b.Write([]byte{0x02, 0x00, 0x00, 0x00})
+	b.Write([]byte{0x01, 0x01})
+	wavReader, err := NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	is.NoErr(err)
+	is.Equal(uint64(1), wavReader.GetSampleCount())
+	sample, err := wavReader.ReadSample()
+	is.NoErr(err)
+	is.Equal(257, sample)
+}
+
+func extensibleFmt(subFormat GUID) []byte {
+	var b bytes.Buffer
+	b.Write(fmt20)
+	b.Write([]byte{0x28, 0x00, 0x00, 0x00}) // LengthOfHeader
+	b.Write([]byte{0xfe, 0xff})             // AudioFormat
+	b.Write([]byte{0x06, 0x00})             // NumOfChannels
+	b.Write([]byte{0x80, 0xbb, 0x00, 0x00}) // SampleRate
+	b.Write([]byte{0x00, 0xca, 0x08, 0x00}) // BytesPerSec
+	b.Write([]byte{0x12, 0x00})             // BytesPerBloc
+	b.Write([]byte{0x18, 0x00})             // BitsPerSample
+	b.Write([]byte{0x16, 0x00})             // cbSize
+	b.Write([]byte{0x14, 0x00})             // ValidBitsPerSample
+	b.Write([]byte{0x3f, 0x00, 0x00, 0x00}) // ChannelMask 5.1
+	b.Write(subFormat[:])
+	return b.Bytes()
+}
+
+func TestParseHeaders_extensible(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	var b bytes.Buffer
+	b.Write(riff)
+	b.Write([]byte{0x3c, 0x00, 0x00, 0x00}) // chunkSize
+	b.Write(wave)
+	b.Write(extensibleFmt(SubFormatGUID(AudioFormatPCM)))
+	b.Write([]byte{0x64, 0x61, 0x74, 0x61}) // "data"
+	b.Write([]byte{0x00, 0x00, 0x00, 0x00})
+	wavReader, err := NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	is.NoErr(err)
+	is.Equal(AudioFormatPCM, wavReader.GetAudioFormat())
+	is.Equal(uint16(20), wavReader.GetValidBits())
+	is.Equal(uint32(0x3f), wavReader.GetChannelMask())
+
+	f := wavReader.GetFile()
+	is.Equal(uint16(6), f.Channels)
+	is.Equal(uint16(24), f.SignificantBits)
+	is.Equal(uint16(20), f.ValidBits)
+	is.Equal("00000001-0000-0010-8000-00aa00389b71", f.SubFormat.String())
+}
+
+func TestParseHeaders_extensibleUnknownSubFormat(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	var b bytes.Buffer
+	b.Write(riff)
+	b.Write([]byte{0x3c, 0x00, 0x00, 0x00}) // chunkSize
+	b.Write(wave)
+	b.Write(extensibleFmt(GUID{1, 2, 3, 4}))
+	b.Write([]byte{0x64, 0x61, 0x74, 0x61}) // "data"
+	b.Write([]byte{0x00, 0x00, 0x00, 0x00})
+	_, err := NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	is.Equal(ErrFormatNotSupported, err)
+}
+
+func rf64Header(magic string, riffSize, dataSize, sampleCount uint64) []byte {
+	var b bytes.Buffer
+	b.WriteString(magic)
+	b.Write([]byte{0xff, 0xff, 0xff, 0xff}) // chunkSize
+	b.Write(wave)
+	b.WriteString("ds64")
+	b.Write([]byte{0x28, 0x00, 0x00, 0x00}) // ds64 with one table entry
+	binary.Write(&b, binary.LittleEndian, riffSize)
+	binary.Write(&b, binary.LittleEndian, dataSize)
+	binary.Write(&b, binary.LittleEndian, sampleCount)
+	b.Write([]byte{0x01, 0x00, 0x00, 0x00}) // TableLength
+	b.WriteString("LGWV")
+	binary.Write(&b, binary.LittleEndian, uint64(0))
+	b.Write(fmt20)
+	b.Write(testRiffChunkFmt)
+	b.Write([]byte{0xff, 0xff, 0xff, 0xff}) // data size is in ds64
+	return b.Bytes()
+}
+
+func TestParseHeaders_rf64(t *testing.T) {
+	t.Parallel()
+	for _, magic := range []string{"RF64", "BW64"} {
+		is := is.New(t)
+		buf := rf64Header(magic, 88, 4, 2)
+		buf = append(buf, 0x01, 0x00, 0xff, 0xff)
+		wavReader, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+		is.NoErr(err)
+		is.Equal(uint64(2), wavReader.GetSampleCount())
+		is.Equal(uint64(4), wavReader.GetFile().SoundSize)
+		sample, err := wavReader.ReadSample()
+		is.NoErr(err)
+		is.Equal(1, sample)
+	}
+}
+
+func TestParseHeaders_rf64Large(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	// only the header is parsed, the samples don't need to exist
+	const dataSize = 6 << 30
+	buf := rf64Header("RF64", 84+dataSize, dataSize, dataSize/2)
+	wavReader, err := NewReader(bytes.NewReader(buf), 92+dataSize)
+	is.NoErr(err)
+	is.Equal(uint64(dataSize/2), wavReader.GetSampleCount())
+}
+
+func TestParseHeaders_rf64WrongSize(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	buf := rf64Header("RF64", 1000, 0, 0)
+	_, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.Equal(ErrIncorrectChunkSize{1008, int64(len(buf))}, err)
+}
+
+func TestParseHeaders_rf64TableTooLarge(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	var b bytes.Buffer
+	b.WriteString("RF64")
+	b.Write([]byte{0xff, 0xff, 0xff, 0xff}) // chunkSize
+	b.Write(wave)
+	b.WriteString("ds64")
+	b.Write([]byte{0xf0, 0xff, 0xff, 0xff}) // far more than the file holds
+	binary.Write(&b, binary.LittleEndian, riffChunkDS64{RiffSize: 40, TableLength: 0xffffffff})
+	is.Equal(48, b.Len())
+	_, err := NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	is.Equal(ErrBrokenChunkDS64, err)
+}
+
+func TestParseHeaders_rf64NoDS64(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	var b bytes.Buffer
+	b.WriteString("RF64")
+	b.Write([]byte{0xff, 0xff, 0xff, 0xff}) // chunkSize
+	b.Write(wave)
+	b.Write(fmt20)
+	b.Write(testRiffChunkFmt)
+	_, err := NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	is.Equal(ErrBrokenChunkDS64, err)
+}
+
+// pcmWave returns a mono WAV file with the given sample container and valid bits
+func pcmWave(bits, validBits uint16, data []byte) []byte {
+	var f bytes.Buffer
+	binary.Write(&f, binary.LittleEndian, riffChunkFmt{
+		LengthOfHeader: 16,
+		AudioFormat:    AudioFormatPCM,
+		NumChannels:    1,
+		SampleRate:     8000,
+		BytesPerSec:    8000 * uint32(bits+7) / 8,
+		BytesPerBloc:   (bits + 7) / 8,
+		BitsPerSample:  bits,
+	})
+	if validBits != 0 {
+		f.Reset()
+		binary.Write(&f, binary.LittleEndian, riffChunkFmt{
+			LengthOfHeader: 40,
+			AudioFormat:    AudioFormatExtensible,
+			NumChannels:    1,
+			SampleRate:     8000,
+			BytesPerSec:    8000 * uint32(bits) / 8,
+			BytesPerBloc:   bits / 8,
+			BitsPerSample:  bits,
+		})
+		binary.Write(&f, binary.LittleEndian, uint16(22))
+		binary.Write(&f, binary.LittleEndian, riffChunkFmtExtensible{
+			ValidBitsPerSample: validBits,
+			SubFormat:          SubFormatGUID(AudioFormatPCM),
+		})
+	}
+
+	var b bytes.Buffer
+	b.Write(riff)
+	binary.Write(&b, binary.LittleEndian, uint32(4+8+f.Len()-4+8+len(data)))
+	b.Write(wave)
+	b.Write(fmt20)
+	b.Write(f.Bytes())
+	b.Write([]byte{0x64, 0x61, 0x74, 0x61}) // "data"
+	binary.Write(&b, binary.LittleEndian, uint32(len(data)))
+	b.Write(data)
+	return b.Bytes()
+}
+
+func TestReadSample_depths(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	for _, tc := range []struct {
+		bits, validBits uint16
+		data            []byte
+		samples         []int32
+	}{
+		{8, 0, []byte{0x00, 0x80, 0xff, 0x7f}, []int32{-128, 0, 127, -1}},
+		{16, 0, []byte{0x00, 0x80, 0xff, 0xff, 0xff, 0x7f}, []int32{-32768, -1, 32767}},
+		{16, 12, []byte{0x00, 0x80, 0xf0, 0xff, 0xf0, 0x7f}, []int32{-2048, -1, 2047}},
+		{20, 0, []byte{0x00, 0x00, 0x80, 0xf0, 0xff, 0xff}, []int32{-524288, -1}},
+		{24, 0, []byte{0x00, 0x00, 0x80, 0xff, 0xff, 0xff, 0x56, 0x34, 0x12}, []int32{-8388608, -1, 0x123456}},
+		{24, 20, []byte{0x00, 0x00, 0x80, 0xf0, 0xff, 0xff, 0xf0, 0xff, 0x7f}, []int32{-524288, -1, 524287}},
+		{32, 0, []byte{0x00, 0x00, 0x00, 0x80, 0xfe, 0xff, 0xff, 0xff}, []int32{-2147483648, -2}},
+	} {
+		buf := pcmWave(tc.bits, tc.validBits, tc.data)
+		wavReader, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+		is.NoErr(err)
+		is.Equal(uint64(len(tc.samples)), wavReader.GetSampleCount())
+		for _, want := range tc.samples {
+			sample, err := wavReader.ReadSample()
+			is.NoErr(err)
+			is.Equal(want, sample)
+		}
+		_, err = wavReader.ReadSample()
+		is.Equal(io.EOF, err)
+	}
+}
+
+func TestParseHeaders_rf64Table(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	buf := rf64Header("RF64", 0, 4, 2)
+	buf[52] = 6 // size of LGWV in the table
+	buf = append(buf, 0x01, 0x00, 0xff, 0xff)
+	buf = append(buf, "LGWV\xff\xff\xff\xff123456"...)
+	buf = append(buf, "DISP\x02\x00\x00\x00hi"...)
+	binary.LittleEndian.PutUint64(buf[20:], uint64(len(buf)-8))
+
+	wavReader, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	chunks := wavReader.Chunks()
+	is.Equal(Chunk{ID: "LGWV", Offset: 104, Size: 6}, chunks[3])
+	is.Equal(Chunk{ID: "DISP", Offset: 118, Size: 2}, chunks[4])
+	raw := wavReader.GetFile().Chunks
+	is.Equal(2, len(raw))
+	for i, want := range []string{"123456", "hi"} {
+		body, err := raw[i].Body()
+		is.NoErr(err)
+		is.Equal(want, string(body))
+		is.True(raw[i].AfterData)
+	}
+}
+
+func TestDuration_frames(t *testing.T) {
+	is := is.New(t)
+
+	// 4001 stereo frames at 8 kHz
+	rd := writeAIFF(t, File{Channels: 2, SampleRate: 8000, SignificantBits: 16}, func(wr *Writer) {
+		for i := 0; i < 2*4001; i++ {
+			is.NoErr(wr.WriteInt32(0))
+		}
+	})
+	is.Equal(500125*time.Microsecond, rd.GetDuration())
+	is.Equal(500125*time.Microsecond, rd.GetFile().Duration)
+}
@@ -0,0 +1,224 @@
+package wav
+
+import (
+	"fmt"
+	"io"
+	"math"
+)
+
+// Speaker positions of the ChannelMask, the channels of a file are stored in this order
+const (
+	SpeakerFrontLeft uint32 = 1 << iota
+	SpeakerFrontRight
+	SpeakerFrontCenter
+	SpeakerLowFrequency
+	SpeakerBackLeft
+	SpeakerBackRight
+	SpeakerFrontLeftOfCenter
+	SpeakerFrontRightOfCenter
+	SpeakerBackCenter
+	SpeakerSideLeft
+	SpeakerSideRight
+)
+
+// Common channel masks
+const (
+	MaskMono    = SpeakerFrontCenter
+	MaskStereo  = SpeakerFrontLeft | SpeakerFrontRight
+	Mask5Point1 = MaskStereo | SpeakerFrontCenter | SpeakerLowFrequency | SpeakerBackLeft | SpeakerBackRight
+)
+
+const (
+	minus3dB  = math.Sqrt2 / 2
+	noChannel = -1
+)
+
+// Matrix describes a remix, Gains[out][in] is the gain of input channel in on output channel out.
+// ChannelMask is the mask of the output, if it is 0 NewRemixer derives it for matrices which only copy channels.
+type Matrix struct {
+	Gains       [][]float64
+	ChannelMask uint32
+}
+
+// Downmix5Point1ToStereo mixes center and surround channels into the front at -3 dB as in ITU-R BS.775.
+// The low frequency channel is dropped. Loud material can clip, the Writer clamps the samples.
+func Downmix5Point1ToStereo() Matrix {
+	return Matrix{
+		Gains: [][]float64{
+			{1, 0, minus3dB, 0, minus3dB, 0},
+			{0, 1, minus3dB, 0, 0, minus3dB},
+		},
+		ChannelMask: MaskStereo,
+	}
+}
+
+// DownmixStereoToMono averages both channels
+func DownmixStereoToMono() Matrix {
+	return Matrix{
+		Gains:       [][]float64{{0.5, 0.5}},
+		ChannelMask: MaskMono,
+	}
+}
+
+// UpmixMonoToStereo copies the channel to both sides
+func UpmixMonoToStereo() Matrix {
+	return Matrix{
+		Gains:       [][]float64{{1}, {1}},
+		ChannelMask: MaskStereo,
+	}
+}
+
+// SelectChannels returns a matrix for in input channels which outputs the given channels in that order.
+// It reorders, duplicates or drops channels, e.g. SelectChannels(2, 1, 0) swaps left and right.
+func SelectChannels(in int, channels ...int) Matrix {
+	gains := make([][]float64, len(channels))
+	for i, ch := range channels {
+		gains[i] = make([]float64, in)
+		if ch >= 0 && ch < in {
+			gains[i][ch] = 1
+		}
+	}
+	return Matrix{Gains: gains}
+}
+
+// Remixer applies a Matrix to the frames of a SampleReader.
+// It is a SampleReader itself and can be passed to Convert to feed a Writer.
+type Remixer struct {
+	src    SampleReader
+	gains  [][]float64
+	file   File
+	in     []float64
+	out    []float64
+	outPos int
+}
+
+// NewRemixer returns a Remixer for src, the matrix needs a column for each channel of src
+func NewRemixer(src SampleReader, m Matrix) (*Remixer, error) {
+	in := src.GetFile()
+	if in.Channels == 0 {
+		return nil, fmt.Errorf("remix source has no channels")
+	}
+	if len(m.Gains) == 0 || len(m.Gains) > math.MaxUint16 {
+		return nil, fmt.Errorf("remix matrix has %d outputs", len(m.Gains))
+	}
+	for _, row := range m.Gains {
+		if len(row) != int(in.Channels) {
+			return nil, fmt.Errorf("remix matrix has %d inputs, need %d", len(row), in.Channels)
+		}
+	}
+
+	r := &Remixer{
+		src:    src,
+		gains:  m.Gains,
+		file:   in,
+		in:     make([]float64, in.Channels),
+		out:    make([]float64, len(m.Gains)),
+		outPos: len(m.Gains),
+	}
+
+	channels := uint64(len(m.Gains))
+	r.file.Channels = uint16(channels)
+	r.file.ChannelMask = m.ChannelMask
+	if m.ChannelMask == 0 {
+		r.file.ChannelMask = copiedMask(m.Gains, in)
+	}
+	if r.file.ChannelMask == defaultMask(r.file.Channels) && r.file.Channels <= 2 {
+		// no need for an extensible header
+		r.file.ChannelMask = 0
+	}
+
+	if in.NumberOfSamples != math.MaxUint64 {
+		frames := in.NumberOfSamples / uint64(in.Channels)
+		r.file.NumberOfSamples = frames * channels
+		r.file.SoundSize = in.SoundSize / uint64(in.Channels) * channels
+		r.file.BytesPerSecond = in.BytesPerSecond / uint32(in.Channels) * uint32(channels)
+	}
+
+	return r, nil
+}
+
+// GetFile returns the File of the source with the new channels and channel mask
+func (r *Remixer) GetFile() File {
+	return r.file
+}
+
+// ReadFloat64 returns the next interleaved sample of the remixed frames
+func (r *Remixer) ReadFloat64() (float64, error) {
+	if r.outPos == len(r.out) {
+		for i := range r.in {
+			x, err := r.src.ReadFloat64()
+			if err != nil {
+				if i != 0 && err == io.EOF {
+					err = io.ErrUnexpectedEOF
+				}
+				return 0, err
+			}
+			r.in[i] = x
+		}
+
+		for o, row := range r.gains {
+			var sum float64
+			for i, g := range row {
+				sum += g * r.in[i]
+			}
+			r.out[o] = sum
+		}
+		r.outPos = 0
+	}
+
+	x := r.out[r.outPos]
+	r.outPos++
+	return x, nil
+}
+
+// copiedMask returns the channel mask for a matrix which only copies input channels.
+// The speakers have to stay in mask order, otherwise the mask is unknown and 0 is returned.
+func copiedMask(gains [][]float64, in File) uint32 {
+	mask := in.ChannelMask
+	if mask == 0 {
+		mask = defaultMask(in.Channels)
+	}
+
+	// speaker of each input channel
+	var speakers []uint32
+	for bit := uint32(1); bit != 0 && len(speakers) < int(in.Channels); bit <<= 1 {
+		if mask&bit != 0 {
+			speakers = append(speakers, bit)
+		}
+	}
+	if len(speakers) != int(in.Channels) {
+		return 0
+	}
+
+	var out, last uint32
+	for _, row := range gains {
+		src := noChannel
+		for i, g := range row {
+			switch {
+			case g == 1 && src == noChannel:
+				src = i
+			case g != 0:
+				return 0
+			}
+		}
+		if src == noChannel || speakers[src] <= last {
+			return 0
+		}
+		last = speakers[src]
+		out |= last
+	}
+	return out
+}
+
+// defaultMask returns the usual speaker positions for files without a channel mask
+func defaultMask(channels uint16) uint32 {
+	switch channels {
+	case 1:
+		return MaskMono
+	case 2:
+		return MaskStereo
+	case 6:
+		return Mask5Point1
+	}
+	return 0
+}
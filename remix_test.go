@@ -0,0 +1,101 @@
+package wav
+
+import (
+	"io"
+	"math"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func readAllFloat(t *testing.T, r SampleReader) []float64 {
+	var out []float64
+	for {
+		x, err := r.ReadFloat64()
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		out = append(out, x)
+	}
+}
+
+func TestRemix_presets(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	src := &sliceReader{
+		file:    File{Channels: 6, SampleRate: 48000, NumberOfSamples: 6, ChannelMask: Mask5Point1},
+		samples: []float64{0.1, 0.2, 0.4, 0.9, 0.2, 0.4},
+	}
+	r, err := NewRemixer(src, Downmix5Point1ToStereo())
+	is.NoErr(err)
+	file := r.GetFile()
+	is.Equal(uint16(2), file.Channels)
+	is.Equal(uint32(0), file.ChannelMask)
+	is.Equal(uint64(2), file.NumberOfSamples)
+
+	out := readAllFloat(t, r)
+	is.Equal(2, len(out))
+	is.True(math.Abs(out[0]-(0.1+0.6*minus3dB)) < 1e-12)
+	is.True(math.Abs(out[1]-(0.2+0.8*minus3dB)) < 1e-12)
+
+	r, err = NewRemixer(&sliceReader{file: File{Channels: 2}, samples: []float64{0.5, -0.25}}, DownmixStereoToMono())
+	is.NoErr(err)
+	is.Equal(uint16(1), r.GetFile().Channels)
+	is.Equal([]float64{0.125}, readAllFloat(t, r))
+
+	r, err = NewRemixer(&sliceReader{file: File{Channels: 1}, samples: []float64{0.5, -0.25}}, UpmixMonoToStereo())
+	is.NoErr(err)
+	is.Equal(uint16(2), r.GetFile().Channels)
+	is.Equal([]float64{0.5, 0.5, -0.25, -0.25}, readAllFloat(t, r))
+}
+
+func TestRemix_select(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	frame := []float64{1, 2, 3, 4, 5, 6}
+	for _, tc := range []struct {
+		channels []int
+		mask     uint32
+		out      []float64
+	}{
+		{[]int{0}, SpeakerFrontLeft, []float64{1}},
+		{[]int{0, 1}, 0, []float64{1, 2}},
+		{[]int{1, 0}, 0, []float64{2, 1}},
+		{[]int{0, 1, 4, 5}, MaskStereo | SpeakerBackLeft | SpeakerBackRight, []float64{1, 2, 5, 6}},
+		{[]int{2, 2}, 0, []float64{3, 3}},
+	} {
+		src := &sliceReader{file: File{Channels: 6}, samples: frame}
+		r, err := NewRemixer(src, SelectChannels(6, tc.channels...))
+		is.NoErr(err)
+		is.Equal(uint16(len(tc.channels)), r.GetFile().Channels)
+		is.Equal(tc.mask, r.GetFile().ChannelMask)
+		is.Equal(tc.out, readAllFloat(t, r))
+	}
+}
+
+func TestRemix_errors(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	_, err := NewRemixer(&sliceReader{file: File{Channels: 2}}, Downmix5Point1ToStereo())
+	is.Err(err)
+
+	_, err = NewRemixer(&sliceReader{file: File{Channels: 2}}, Matrix{})
+	is.Err(err)
+
+	// without input channels the matrix would output zeros forever
+	_, err = NewRemixer(&sliceReader{file: File{}}, Matrix{Gains: [][]float64{{}}})
+	is.Err(err)
+
+	r, err := NewRemixer(&sliceReader{file: File{Channels: 2}, samples: []float64{1, 2, 3}}, DownmixStereoToMono())
+	is.NoErr(err)
+	_, err = r.ReadFloat64()
+	is.NoErr(err)
+	_, err = r.ReadFloat64()
+	is.Equal(io.ErrUnexpectedEOF, err)
+}
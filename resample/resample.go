@@ -0,0 +1,276 @@
+// Package resample converts the sample rate of wav streams with band-limited windowed-sinc interpolation.
+//
+// A resample.Reader wraps any wav.SampleReader, e.g. a *wav.Reader, and is one itself.
+// To feed a Writer pass it to wav.Convert.
+package resample
+
+import (
+	"errors"
+	"io"
+	"math"
+	"time"
+
+	"github.com/cryptix/wav"
+)
+
+// Quality selects the length and the steepness of the interpolation filter
+type Quality int
+
+const (
+	// Low is fast, with a 8 zero crossing filter
+	Low Quality = iota
+	// Medium is a good default
+	Medium
+	// High has a 32 zero crossing filter and little aliasing
+	High
+	// Best is for mastering, it is slow
+	Best
+)
+
+type preset struct {
+	zeros   int     // zero crossings on each side of the filter
+	rolloff float64 // cutoff relative to the lower Nyquist frequency
+	beta    float64 // of the Kaiser window
+}
+
+var presets = [...]preset{
+	Low:    {8, 0.85, 6},
+	Medium: {16, 0.9, 8},
+	High:   {32, 0.95, 10},
+	Best:   {64, 0.97, 12},
+}
+
+// maxTable is the number of coefficients up to which all filter phases are precomputed
+const maxTable = 1 << 20
+
+// ErrQuality is returned for unknown Quality values
+var ErrQuality = errors.New("resample: unknown quality")
+
+// Reader resamples the samples of a wav.SampleReader to a new rate.
+// It only buffers the input frames covered by the filter.
+type Reader struct {
+	src      wav.SampleReader
+	file     wav.File
+	channels int
+
+	up, down uint64 // the output rate is up/down times the input rate
+	half     int    // filter taps on each side of the interpolation point
+	cutoff   float64
+	beta     float64
+	table    [][]float64 // coefficients for each phase, nil if computed on the fly
+	coefs    []float64
+
+	// the input, buf holds frames starting at frame base
+	buf   []float64
+	base  int64
+	total int64 // number of input frames, -1 until EOF
+
+	// the position of the next output frame is pos + phase/up input frames
+	pos   int64
+	phase uint64
+
+	frame []float64 // the current output frame
+	idx   int
+}
+
+// New returns a Reader producing src at rate Hz
+func New(src wav.SampleReader, rate uint32, quality Quality) (*Reader, error) {
+	if quality < Low || quality > Best {
+		return nil, ErrQuality
+	}
+
+	in := src.GetFile()
+	if in.Channels == 0 || in.SampleRate == 0 || rate == 0 {
+		return nil, errors.New("resample: need channels and sample rates")
+	}
+
+	g := gcd(uint64(rate), uint64(in.SampleRate))
+	r := &Reader{
+		src:      src,
+		channels: int(in.Channels),
+		up:       uint64(rate) / g,
+		down:     uint64(in.SampleRate) / g,
+		total:    -1,
+		idx:      int(in.Channels),
+	}
+	r.frame = make([]float64, r.channels)
+
+	p := presets[quality]
+	r.cutoff = p.rolloff
+	if r.down > r.up {
+		// downsampling has to filter below the new Nyquist frequency
+		r.cutoff *= float64(r.up) / float64(r.down)
+	}
+	r.half = int(math.Ceil(float64(p.zeros) / r.cutoff))
+	r.beta = p.beta
+	r.coefs = make([]float64, 2*r.half)
+
+	if r.up*uint64(2*r.half) <= maxTable {
+		r.table = make([][]float64, r.up)
+		for phase := range r.table {
+			r.table[phase] = r.phaseCoefs(uint64(phase), make([]float64, 2*r.half))
+		}
+	}
+
+	r.file = in
+	r.file.SampleRate = rate
+	if in.NumberOfSamples != math.MaxUint64 {
+		frames := in.NumberOfSamples / uint64(in.Channels)
+		frames = (frames*r.up + r.down - 1) / r.down
+		r.file.NumberOfSamples = frames * uint64(in.Channels)
+		r.file.Duration = time.Duration(float64(frames) / float64(rate) * float64(time.Second))
+		if in.NumberOfSamples > 0 {
+			r.file.SoundSize = in.SoundSize / in.NumberOfSamples * r.file.NumberOfSamples
+		}
+	}
+	r.file.BytesPerSecond = uint32(uint64(in.BytesPerSecond) * r.up / r.down)
+
+	return r, nil
+}
+
+// GetFile returns the File of the source with the new sample rate, sizes and duration
+func (r *Reader) GetFile() wav.File {
+	return r.file
+}
+
+// ReadFloat64 returns the next interleaved sample
+func (r *Reader) ReadFloat64() (float64, error) {
+	if r.idx == r.channels {
+		if err := r.next(); err != nil {
+			return 0, err
+		}
+		r.idx = 0
+	}
+	x := r.frame[r.idx]
+	r.idx++
+	return x, nil
+}
+
+// next computes the next output frame
+func (r *Reader) next() error {
+	// the filter reaches from pos-half+1 to pos+half
+	first := r.pos - int64(r.half) + 1
+	last := r.pos + int64(r.half)
+
+	for r.total < 0 && r.base+int64(len(r.buf)/r.channels) <= last {
+		if err := r.fill(); err != nil {
+			return err
+		}
+	}
+
+	if r.total >= 0 && r.pos >= r.total {
+		return io.EOF
+	}
+
+	// drop frames the filter has passed
+	if drop := first - r.base; drop > int64(r.half) {
+		r.buf = append(r.buf[:0], r.buf[int(drop)*r.channels:]...)
+		r.base += drop
+	}
+
+	coefs := r.coefs
+	if r.table != nil {
+		coefs = r.table[r.phase]
+	} else {
+		r.phaseCoefs(r.phase, coefs)
+	}
+
+	for c := range r.frame {
+		var sum float64
+		for j, coef := range coefs {
+			k := first + int64(j) - r.base
+			if k < 0 || k >= int64(len(r.buf)/r.channels) {
+				// silence before the start and after the end
+				continue
+			}
+			sum += r.buf[int(k)*r.channels+c] * coef
+		}
+		r.frame[c] = sum
+	}
+
+	r.phase += r.down
+	r.pos += int64(r.phase / r.up)
+	r.phase %= r.up
+	return nil
+}
+
+// fill reads the next input frame
+func (r *Reader) fill() error {
+	for c := 0; c < r.channels; c++ {
+		x, err := r.src.ReadFloat64()
+		if err == io.EOF {
+			if c != 0 {
+				return io.ErrUnexpectedEOF
+			}
+			r.total = r.base + int64(len(r.buf)/r.channels)
+			return nil
+		} else if err != nil {
+			return err
+		}
+		r.buf = append(r.buf, x)
+	}
+	return nil
+}
+
+// phaseCoefs fills coefs with the filter for the output position phase/up after an input frame
+func (r *Reader) phaseCoefs(phase uint64, coefs []float64) []float64 {
+	if r.up == r.down {
+		// no interpolation needed
+		for j := range coefs {
+			coefs[j] = 0
+		}
+		coefs[r.half-1] = 1
+		return coefs
+	}
+
+	frac := float64(phase) / float64(r.up)
+	var sum float64
+	for j := range coefs {
+		// distance of the tap from the output position
+		d := float64(r.half-1-j) + frac
+		coefs[j] = r.cutoff * sinc(r.cutoff*d) * kaiser(d/float64(r.half), r.beta)
+		sum += coefs[j]
+	}
+
+	// unity gain at DC for every phase
+	for j := range coefs {
+		coefs[j] /= sum
+	}
+	return coefs
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	x *= math.Pi
+	return math.Sin(x) / x
+}
+
+// kaiser returns the Kaiser window at x in [-1, 1]
+func kaiser(x, beta float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return besselI0(beta*math.Sqrt(1-x*x)) / besselI0(beta)
+}
+
+// besselI0 is the modified Bessel function of the first kind of order zero
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; k < 50; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+		if term < sum*1e-17 {
+			break
+		}
+	}
+	return sum
+}
+
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
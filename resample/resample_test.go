@@ -0,0 +1,148 @@
+package resample
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/cheekybits/is"
+	"github.com/cryptix/wav"
+)
+
+// sineReader supplies a sine of freq Hz on every channel
+type sineReader struct {
+	file wav.File
+	freq float64
+	n    uint64
+}
+
+func newSine(rate uint32, channels uint16, freq float64, frames uint64) *sineReader {
+	return &sineReader{
+		file: wav.File{
+			SampleRate:      rate,
+			Channels:        channels,
+			SignificantBits: 32,
+			AudioFormat:     wav.AudioFormatIEEEFloat,
+			NumberOfSamples: frames * uint64(channels),
+			SoundSize:       frames * uint64(channels) * 4,
+			BytesPerSecond:  rate * uint32(channels) * 4,
+		},
+		freq: freq,
+	}
+}
+
+func (s *sineReader) GetFile() wav.File { return s.file }
+
+func (s *sineReader) ReadFloat64() (float64, error) {
+	if s.n >= s.file.NumberOfSamples {
+		return 0, io.EOF
+	}
+	frame := s.n / uint64(s.file.Channels)
+	s.n++
+	return 0.5 * math.Sin(2*math.Pi*s.freq*float64(frame)/float64(s.file.SampleRate)), nil
+}
+
+// readAll returns the frames of channel ch
+func readAll(t *testing.T, r wav.SampleReader, ch int) []float64 {
+	channels := int(r.GetFile().Channels)
+	var out []float64
+	for i := 0; ; i++ {
+		x, err := r.ReadFloat64()
+		if err == io.EOF {
+			return out
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i%channels == ch {
+			out = append(out, x)
+		}
+	}
+}
+
+func TestResample_rates(t *testing.T) {
+	is := is.New(t)
+
+	for _, tc := range []struct {
+		from, to uint32
+		quality  Quality
+	}{
+		{44100, 48000, Medium},
+		{48000, 44100, High},
+		{96000, 48000, Low},
+		{44100, 96000, Best},
+		{8000, 8000, Medium},
+	} {
+		const freq = 1000
+		src := newSine(tc.from, 2, freq, uint64(tc.from/10))
+		r, err := New(src, tc.to, tc.quality)
+		is.NoErr(err)
+
+		file := r.GetFile()
+		is.Equal(tc.to, file.SampleRate)
+		is.Equal(uint64(2*tc.to/10), file.NumberOfSamples)
+		is.Equal(int64(100), file.Duration.Nanoseconds()/1e6)
+
+		out := readAll(t, r, 1)
+		is.Equal(int(tc.to/10), len(out))
+
+		// away from the edges the output is the same sine at the new rate
+		var maxErr float64
+		for n := len(out) / 4; n < 3*len(out)/4; n++ {
+			want := 0.5 * math.Sin(2*math.Pi*freq*float64(n)/float64(tc.to))
+			maxErr = math.Max(maxErr, math.Abs(out[n]-want))
+		}
+		if maxErr > 1e-3 {
+			t.Errorf("%d -> %d: error %g", tc.from, tc.to, maxErr)
+		}
+	}
+}
+
+func TestResample_antiAliasing(t *testing.T) {
+	is := is.New(t)
+
+	// 30 kHz is above the Nyquist frequency of 44.1 kHz and has to vanish
+	r, err := New(newSine(96000, 1, 30000, 9600), 44100, High)
+	is.NoErr(err)
+	out := readAll(t, r, 0)
+	for n := len(out) / 4; n < 3*len(out)/4; n++ {
+		if math.Abs(out[n]) > 1e-3 {
+			t.Fatalf("sample %d: %g", n, out[n])
+		}
+	}
+}
+
+func TestResample_writer(t *testing.T) {
+	is := is.New(t)
+
+	var b bytes.Buffer
+	b.Write([]byte{0x00, 0x40, 0x00, 0x40, 0x00, 0x40, 0x00, 0x40})
+	rd, err := wav.File{SampleRate: 8000, Channels: 1, SignificantBits: 16}.NewRawReader(&b, int64(b.Len()))
+	is.NoErr(err)
+
+	r, err := New(rd, 16000, Medium)
+	is.NoErr(err)
+
+	var out bytes.Buffer
+	wr, err := r.GetFile().NewRawWriter(&out)
+	is.NoErr(err)
+	n, err := wav.Convert(wr, r, wav.DitherNone, 0)
+	is.NoErr(err)
+	is.NoErr(wr.Close())
+	is.Equal(uint64(8), n)
+
+	// the filter sees silence around the four input frames
+	samples := make([]int16, 8)
+	is.NoErr(binary.Read(&out, binary.LittleEndian, samples))
+	for _, s := range samples[:6] {
+		is.True(s > 0x3000 && s < 0x5000)
+	}
+}
+
+func TestResample_quality(t *testing.T) {
+	is := is.New(t)
+	_, err := New(newSine(8000, 1, 100, 10), 16000, Quality(9))
+	is.Equal(ErrQuality, err)
+}
@@ -1,6 +1,8 @@
 package wav
 
 import (
+	"bytes"
+	"encoding/binary"
 	"io/ioutil"
 	"math"
 	"os"
@@ -114,3 +116,191 @@ func TestWriteRead_Sample(t *testing.T) {
 
 	is.NoErr(os.Remove(testFname))
 }
+
+func TestWriteRead_Float(t *testing.T) {
+	for _, bits := range []uint16{32, 64} {
+		is := is.New(t)
+
+		f, err := ioutil.TempFile("", "wavPkgtest")
+		is.NoErr(err)
+
+		testFname := f.Name()
+
+		meta := File{
+			Channels:        1,
+			SampleRate:      48000,
+			SignificantBits: bits,
+			AudioFormat:     AudioFormatIEEEFloat,
+		}
+
+		writer, err := meta.NewWriter(f)
+		is.NoErr(err)
+
+		samples := []float64{0, 0.5, -0.25, 1, -1, 0.125}
+		for _, s := range samples {
+			is.NoErr(writer.WriteFloat64(s))
+		}
+		is.NoErr(writer.Close())
+
+		f, err = os.Open(testFname)
+		is.NoErr(err)
+
+		stat, err := f.Stat()
+		is.NoErr(err)
+		is.Equal(stat.Size(), 94+int64(len(samples))*int64(bits/8))
+
+		rd, err := NewReader(f, stat.Size())
+		is.NoErr(err)
+		is.Equal(AudioFormatIEEEFloat, rd.GetAudioFormat())
+		is.Equal(uint64(len(samples)), rd.GetSampleCount())
+
+		for _, s := range samples {
+			v, err := rd.ReadFloat64()
+			is.NoErr(err)
+			is.Equal(s, v)
+		}
+
+		_, err = rd.ReadSample()
+		is.Equal(ErrSampleType, err)
+
+		is.NoErr(os.Remove(testFname))
+	}
+}
+
+func TestWriteRead_Extensible(t *testing.T) {
+	is := is.New(t)
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+
+	testFname := f.Name()
+
+	meta := File{
+		Channels:        6,
+		SampleRate:      48000,
+		SignificantBits: 24,
+		ValidBits:       20,
+		ChannelMask:     0x3f,
+	}
+
+	writer, err := meta.NewWriter(f)
+	is.NoErr(err)
+
+	for n := 0; n < 6; n++ {
+		is.NoErr(writer.WriteSample([]byte{0, byte(n), 0}))
+	}
+	is.NoErr(writer.Close())
+
+	f, err = os.Open(testFname)
+	is.NoErr(err)
+
+	stat, err := f.Stat()
+	is.NoErr(err)
+
+	rd, err := NewReader(f, stat.Size())
+	is.NoErr(err)
+
+	got := rd.GetFile()
+	is.Equal(AudioFormatPCM, got.AudioFormat)
+	is.Equal(uint16(6), got.Channels)
+	is.Equal(uint16(20), got.ValidBits)
+	is.Equal(uint32(0x3f), got.ChannelMask)
+	is.Equal(SubFormatGUID(AudioFormatPCM), got.SubFormat)
+	is.Equal(uint64(6), got.NumberOfSamples)
+
+	is.NoErr(os.Remove(testFname))
+}
+
+func TestWriteRead_RIFX(t *testing.T) {
+	is := is.New(t)
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+
+	testFname := f.Name()
+
+	meta := File{
+		Channels:        1,
+		SampleRate:      8000,
+		SignificantBits: 32,
+		ByteOrder:       binary.BigEndian,
+	}
+
+	writer, err := meta.NewWriter(f)
+	is.NoErr(err)
+
+	samples := []int32{1, 0x01020304, 0x7fffffff}
+	for _, s := range samples {
+		is.NoErr(writer.WriteInt32(s))
+	}
+	is.NoErr(writer.Close())
+
+	buf, err := ioutil.ReadFile(testFname)
+	is.NoErr(err)
+	is.Equal("RIFX", string(buf[:4]))
+	is.Equal(uint32(len(buf)-8), binary.BigEndian.Uint32(buf[4:]))
+	is.Equal([]byte{1, 2, 3, 4}, buf[len(buf)-8:len(buf)-4])
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+
+	file := rd.GetFile()
+	is.Equal(binary.BigEndian, file.ByteOrder)
+	is.Equal(uint32(8000), file.SampleRate)
+	is.Equal(uint64(len(samples)), file.NumberOfSamples)
+
+	for _, s := range samples {
+		v, err := rd.ReadSample()
+		is.NoErr(err)
+		is.Equal(s, v)
+	}
+
+	is.NoErr(os.Remove(testFname))
+}
+
+func TestWriteRead_Depths(t *testing.T) {
+	is := is.New(t)
+
+	for _, meta := range []File{
+		{Channels: 1, SampleRate: 8000, SignificantBits: 8},
+		{Channels: 1, SampleRate: 8000, SignificantBits: 16},
+		{Channels: 1, SampleRate: 8000, SignificantBits: 16, ValidBits: 12},
+		{Channels: 1, SampleRate: 8000, SignificantBits: 20},
+		{Channels: 1, SampleRate: 8000, SignificantBits: 24},
+		{Channels: 1, SampleRate: 8000, SignificantBits: 24, ValidBits: 20},
+		{Channels: 1, SampleRate: 8000, SignificantBits: 24, ByteOrder: binary.BigEndian},
+	} {
+		f, err := ioutil.TempFile("", "wavPkgtest")
+		is.NoErr(err)
+
+		testFname := f.Name()
+
+		valid := meta.ValidBits
+		if valid == 0 {
+			valid = meta.SignificantBits
+		}
+		max := int32(1)<<(valid-1) - 1
+		samples := []int32{0, 1, -1, max, -max - 1}
+
+		writer, err := meta.NewWriter(f)
+		is.NoErr(err)
+		for _, s := range samples {
+			is.NoErr(writer.WriteInt32(s))
+		}
+		is.NoErr(writer.Close())
+
+		buf, err := ioutil.ReadFile(testFname)
+		is.NoErr(err)
+
+		rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+		is.NoErr(err)
+		is.Equal(uint64(len(samples)), rd.GetSampleCount())
+		for _, s := range samples {
+			v, err := rd.ReadSample()
+			is.NoErr(err)
+			is.Equal(s, v)
+		}
+
+		is.NoErr(os.Remove(testFname))
+	}
+}
@@ -0,0 +1,172 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+var (
+	tokenSmpl = [4]byte{'s', 'm', 'p', 'l'}
+	tokenInst = [4]byte{'i', 'n', 's', 't'}
+)
+
+// Loop types of a SampleLoop
+const (
+	LoopForward     uint32 = 0
+	LoopAlternating uint32 = 1
+	LoopBackward    uint32 = 2
+)
+
+// Sampler is the smpl chunk with the parameters for playing the file in a sampler
+type Sampler struct {
+	Manufacturer      uint32 // MIDI manufacturer code
+	Product           uint32
+	SamplePeriod      uint32 // in nanoseconds
+	MIDIUnityNote     uint32 // the note played at the original pitch
+	MIDIPitchFraction uint32 // fraction of a semitone above MIDIUnityNote, 0x80000000 is 50 cents
+	SMPTEFormat       uint32 // 0, 24, 25, 29 or 30 frames per second
+	SMPTEOffset       uint32 // 0xhhmmssff
+	Loops             []SampleLoop
+	SamplerData       []byte // manufacturer specific
+}
+
+// SampleLoop is a loop of a Sampler, Start and End are sample frames and End is played
+type SampleLoop struct {
+	CuePointID uint32
+	Type       uint32
+	Start      uint32
+	End        uint32
+	Fraction   uint32
+	PlayCount  uint32 // 0 loops forever
+}
+
+// Instrument is the inst chunk with the key and velocity range of the sample
+type Instrument struct {
+	UnshiftedNote uint8
+	FineTune      int8 // in cents
+	Gain          int8 // in dB
+	LowNote       uint8
+	HighNote      uint8
+	LowVelocity   uint8
+	HighVelocity  uint8
+}
+
+// 36 bytes in front of the loops
+type smplHeader struct {
+	Manufacturer      uint32
+	Product           uint32
+	SamplePeriod      uint32
+	MIDIUnityNote     uint32
+	MIDIPitchFraction uint32
+	SMPTEFormat       uint32
+	SMPTEOffset       uint32
+	NumSampleLoops    uint32
+	SamplerData       uint32
+}
+
+// GetSampler returns the smpl chunk, nil if the file has none
+func (wav *Reader) GetSampler() *Sampler {
+	return wav.sampler
+}
+
+// GetInstrument returns the inst chunk, nil if the file has none
+func (wav *Reader) GetInstrument() *Instrument {
+	return wav.instrument
+}
+
+// parseChunkSmpl reads a smpl chunk of chunkSize bytes, broken and truncated chunks are ignored
+func (wav *Reader) parseChunkSmpl(chunkSize uint32) error {
+	pos, err := wav.input.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return err
+	}
+	if chunkSize < 36 || int64(chunkSize) > wav.size-pos {
+		return nil
+	}
+
+	body := make([]byte, chunkSize)
+	if _, err = io.ReadFull(wav.input, body); err != nil {
+		return err
+	}
+
+	rd := bytes.NewReader(body)
+	var h smplHeader
+	binary.Read(rd, wav.order, &h)
+	if uint64(h.NumSampleLoops)*24+uint64(h.SamplerData) > uint64(rd.Len()) {
+		return nil
+	}
+
+	s := &Sampler{
+		Manufacturer:      h.Manufacturer,
+		Product:           h.Product,
+		SamplePeriod:      h.SamplePeriod,
+		MIDIUnityNote:     h.MIDIUnityNote,
+		MIDIPitchFraction: h.MIDIPitchFraction,
+		SMPTEFormat:       h.SMPTEFormat,
+		SMPTEOffset:       h.SMPTEOffset,
+	}
+	if h.NumSampleLoops > 0 {
+		s.Loops = make([]SampleLoop, h.NumSampleLoops)
+		binary.Read(rd, wav.order, s.Loops)
+	}
+	if h.SamplerData > 0 {
+		s.SamplerData = make([]byte, h.SamplerData)
+		rd.Read(s.SamplerData)
+	}
+
+	wav.sampler = s
+	return nil
+}
+
+// parseChunkInst reads an inst chunk of chunkSize bytes, broken and truncated chunks are ignored
+func (wav *Reader) parseChunkInst(chunkSize uint32) error {
+	pos, err := wav.input.Seek(0, os.SEEK_CUR)
+	if err != nil {
+		return err
+	}
+	if chunkSize < 7 || int64(chunkSize) > wav.size-pos {
+		return nil
+	}
+
+	inst := new(Instrument)
+	if err = binary.Read(wav.input, wav.order, inst); err != nil {
+		return err
+	}
+
+	wav.instrument = inst
+	return nil
+}
+
+// chunk returns the smpl chunk
+func (s *Sampler) chunk(order binary.ByteOrder) []byte {
+	var body bytes.Buffer
+	binary.Write(&body, order, smplHeader{
+		Manufacturer:      s.Manufacturer,
+		Product:           s.Product,
+		SamplePeriod:      s.SamplePeriod,
+		MIDIUnityNote:     s.MIDIUnityNote,
+		MIDIPitchFraction: s.MIDIPitchFraction,
+		SMPTEFormat:       s.SMPTEFormat,
+		SMPTEOffset:       s.SMPTEOffset,
+		NumSampleLoops:    uint32(len(s.Loops)),
+		SamplerData:       uint32(len(s.SamplerData)),
+	})
+	binary.Write(&body, order, s.Loops)
+	body.Write(s.SamplerData)
+
+	var b bytes.Buffer
+	writeChunk(&b, order, tokenSmpl, body.Bytes())
+	return b.Bytes()
+}
+
+// chunk returns the inst chunk
+func (inst *Instrument) chunk(order binary.ByteOrder) []byte {
+	var body bytes.Buffer
+	binary.Write(&body, order, inst)
+
+	var b bytes.Buffer
+	writeChunk(&b, order, tokenInst, body.Bytes())
+	return b.Bytes()
+}
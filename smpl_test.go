@@ -0,0 +1,108 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func TestWriteReadSampler(t *testing.T) {
+	is := is.New(t)
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+	defer os.Remove(f.Name())
+
+	sampler := &Sampler{
+		SamplePeriod:      20833,
+		MIDIUnityNote:     60,
+		MIDIPitchFraction: 0x80000000,
+		SMPTEFormat:       25,
+		SMPTEOffset:       0x01020304,
+		Loops: []SampleLoop{
+			{CuePointID: 1, Type: LoopForward, Start: 100, End: 999},
+			{CuePointID: 2, Type: LoopAlternating, Start: 1000, End: 1999, PlayCount: 3},
+		},
+		SamplerData: []byte{1, 2, 3},
+	}
+	inst := &Instrument{
+		UnshiftedNote: 60,
+		FineTune:      -12,
+		Gain:          -3,
+		LowNote:       48,
+		HighNote:      72,
+		LowVelocity:   1,
+		HighVelocity:  127,
+	}
+	meta := File{
+		Channels:        1,
+		SampleRate:      48000,
+		SignificantBits: 16,
+		Sampler:         sampler,
+		Instrument:      inst,
+	}
+	wr, err := meta.NewWriter(f)
+	is.NoErr(err)
+	is.NoErr(wr.WriteInt32(-5))
+	is.NoErr(wr.Close())
+
+	buf, err := ioutil.ReadFile(f.Name())
+	is.NoErr(err)
+
+	// inst has seven bytes and a pad byte
+	i := bytes.Index(buf, tokenInst[:])
+	is.Equal(uint32(7), binary.LittleEndian.Uint32(buf[i+4:]))
+	is.Equal([]byte{60, 0xf4, 0xfd, 48, 72, 1, 127, 0}, buf[i+8:i+16])
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Equal(sampler, rd.GetSampler())
+	is.Equal(inst, rd.GetInstrument())
+	is.Equal(sampler, rd.GetFile().Sampler)
+	is.Equal(inst, rd.GetFile().Instrument)
+
+	s, err := rd.ReadSample()
+	is.NoErr(err)
+	is.Equal(int32(-5), s)
+}
+
+func TestParseSampler_broken(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	// more loops than the chunk holds
+	var body bytes.Buffer
+	binary.Write(&body, binary.LittleEndian, smplHeader{NumSampleLoops: 5})
+	var c bytes.Buffer
+	writeChunk(&c, binary.LittleEndian, tokenSmpl, body.Bytes())
+	writeChunk(&c, binary.LittleEndian, tokenInst, []byte{1, 2})
+	buf := infoWave(c.Bytes())
+
+	rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+	is.NoErr(err)
+	is.Nil(rd.GetSampler())
+	is.Nil(rd.GetInstrument())
+}
+
+func TestParseSampler_truncated(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	for _, c := range [][]byte{
+		append([]byte("smpl\x40\x00\x00\x00"), make([]byte, 40)...),
+		[]byte("inst\x07\x00\x00\x00\x3c\x00"),
+	} {
+		buf := infoWave(c)
+		rd, err := NewReader(bytes.NewReader(buf), int64(len(buf)))
+		is.NoErr(err)
+		is.Nil(rd.GetSampler())
+		is.Nil(rd.GetInstrument())
+		s, err := rd.ReadSample()
+		is.NoErr(err)
+		is.Equal(int32(1), s)
+	}
+}
@@ -0,0 +1,185 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"os"
+)
+
+// Sony Wave64 uses GUIDs as chunk IDs and 64 bit sizes, which include the 24 byte chunk header.
+// Chunks are aligned to 8 bytes.
+var (
+	tokenW64Riff = [4]byte{'r', 'i', 'f', 'f'}
+
+	guidW64Riff = GUID{0x72, 0x69, 0x66, 0x66, 0x2E, 0x91, 0xCF, 0x11, 0xA5, 0xD6, 0x28, 0xDB, 0x04, 0xC1, 0x00, 0x00}
+	guidW64Wave = GUID{0x77, 0x61, 0x76, 0x65, 0xF3, 0xAC, 0xD3, 0x11, 0x8C, 0xD1, 0x00, 0xC0, 0x4F, 0x8E, 0xDB, 0x8A}
+	guidW64Fmt  = GUID{0x66, 0x6D, 0x74, 0x20, 0xF3, 0xAC, 0xD3, 0x11, 0x8C, 0xD1, 0x00, 0xC0, 0x4F, 0x8E, 0xDB, 0x8A}
+	guidW64Fact = GUID{0x66, 0x61, 0x63, 0x74, 0xF3, 0xAC, 0xD3, 0x11, 0x8C, 0xD1, 0x00, 0xC0, 0x4F, 0x8E, 0xDB, 0x8A}
+	guidW64Data = GUID{0x64, 0x61, 0x74, 0x61, 0xF3, 0xAC, 0xD3, 0x11, 0x8C, 0xD1, 0x00, 0xC0, 0x4F, 0x8E, 0xDB, 0x8A}
+)
+
+// 40 byte Wave64 header
+type w64Header struct {
+	Riff GUID
+	Size uint64
+	Wave GUID
+}
+
+// 24 byte Wave64 chunk header
+type w64ChunkHeader struct {
+	ID   GUID
+	Size uint64
+}
+
+// parseW64Headers is called by parseHeaders, if the file starts with the Wave64 riff GUID
+func (wav *Reader) parseW64Headers() (err error) {
+	if _, err = wav.input.Seek(0, os.SEEK_SET); err != nil {
+		return err
+	}
+
+	var header w64Header
+	if err = binary.Read(wav.input, binary.LittleEndian, &header); err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	if header.Riff != guidW64Riff {
+		return ErrNotRiff
+	}
+
+	if int64(header.Size) != wav.size {
+		return ErrIncorrectChunkSize{int64(header.Size), wav.size}
+	}
+
+	if header.Wave != guidW64Wave {
+		return ErrNotWave
+	}
+
+	for {
+		var chunk w64ChunkHeader
+		err = binary.Read(wav.input, binary.LittleEndian, &chunk)
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		} else if err != nil {
+			return err
+		}
+
+		if chunk.Size < 24 {
+			return ErrIncorrectChunkSize{int64(chunk.Size), 24}
+		}
+		bodySize := chunk.Size - 24
+
+		switch chunk.ID {
+		case guidW64Fmt:
+			if bodySize > 0xFFFF {
+				return ErrBrokenChunkFmt
+			}
+			if err = wav.parseChunkFmt(uint32(bodySize)); err != nil {
+				return err
+			}
+		case guidW64Fact:
+			wav.extraChunk = true
+			var samples uint64
+			switch {
+			case bodySize >= 8:
+				err = binary.Read(wav.input, binary.LittleEndian, &samples)
+				bodySize -= 8
+			case bodySize >= 4:
+				var samples32 uint32
+				err = binary.Read(wav.input, binary.LittleEndian, &samples32)
+				samples = uint64(samples32)
+				bodySize -= 4
+			default:
+				return ErrBrokenChunkFact
+			}
+			if err != nil {
+				return err
+			}
+			wav.hasFact = true
+			wav.factSamples = samples
+			if _, err = wav.input.Seek(int64(bodySize), os.SEEK_CUR); err != nil {
+				return err
+			}
+		case guidW64Data:
+			size, _ := wav.input.Seek(0, os.SEEK_CUR)
+			wav.firstSamplePos = uint32(size)
+			wav.dataBlocSize = bodySize
+			if wav.chunkFmt == nil {
+				return ErrBrokenChunkFmt
+			}
+			return wav.setupSamples()
+		default:
+			wav.extraChunk = true
+			if _, err = wav.input.Seek(int64(bodySize), os.SEEK_CUR); err != nil {
+				return err
+			}
+		}
+
+		// skip to the next 8 byte boundary
+		if pad := (8 - chunk.Size%8) % 8; pad > 0 {
+			if _, err = wav.input.Seek(int64(pad), os.SEEK_CUR); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// NewW64Writer creates a new Writer for Sony Wave64 files and writes the header to it.
+// Wave64 uses 64 bit sizes, so large files don't need RF64.
+func (file File) NewW64Writer(out output) (wr *Writer, err error) {
+	if err = file.checkWAV(); err != nil {
+		return nil, err
+	}
+
+	wr = newWriter(out, file, binary.LittleEndian)
+	wr.align = 8
+	wr.finish = wr.finishW64
+
+	// sizes are zero for now and get corrected on Close
+	var hdr bytes.Buffer
+	binary.Write(&hdr, binary.LittleEndian, w64Header{
+		Riff: guidW64Riff,
+		Wave: guidW64Wave,
+	})
+
+	chunkFmt := file.chunkFmt(binary.LittleEndian)
+	binary.Write(&hdr, binary.LittleEndian, w64ChunkHeader{
+		ID:   guidW64Fmt,
+		Size: uint64(24 + len(chunkFmt)),
+	})
+	hdr.Write(chunkFmt)
+	hdr.Write(make([]byte, (8-len(chunkFmt)%8)%8))
+
+	// non-PCM formats need a fact chunk
+	if file.AudioFormat != AudioFormatPCM {
+		binary.Write(&hdr, binary.LittleEndian, w64ChunkHeader{
+			ID:   guidW64Fact,
+			Size: 24 + 8,
+		})
+		wr.framesPos = int64(hdr.Len())
+		binary.Write(&hdr, binary.LittleEndian, uint64(0))
+	}
+
+	binary.Write(&hdr, binary.LittleEndian, w64ChunkHeader{ID: guidW64Data})
+
+	return wr, wr.writeHeader(&hdr)
+}
+
+// finishW64 writes the riff and data sizes and the number of sample frames
+func (w *Writer) finishW64() error {
+	if err := w.patch(16, binary.LittleEndian, uint64(w.headerSize+w.bytesWritten+w.padding())); err != nil {
+		return err
+	}
+
+	if w.framesPos != 0 {
+		frameSize := int64(w.options.containerBytes()) * int64(w.options.Channels)
+		if err := w.patch(w.framesPos, binary.LittleEndian, uint64(w.bytesWritten/frameSize)); err != nil {
+			return err
+		}
+	}
+
+	return w.patch(w.headerSize-8, binary.LittleEndian, uint64(24+w.bytesWritten))
+}
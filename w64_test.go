@@ -0,0 +1,110 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/cheekybits/is"
+)
+
+func writeW64(t *testing.T, meta File, write func(*Writer)) []byte {
+	is := is.New(t)
+
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+	defer os.Remove(f.Name())
+
+	wr, err := meta.NewW64Writer(f)
+	is.NoErr(err)
+	write(wr)
+	is.NoErr(wr.Close())
+
+	b, err := ioutil.ReadFile(f.Name())
+	is.NoErr(err)
+	return b
+}
+
+func TestWriteReadW64(t *testing.T) {
+	is := is.New(t)
+	meta := File{
+		Channels:        1,
+		SampleRate:      44100,
+		SignificantBits: 16,
+	}
+	b := writeW64(t, meta, func(wr *Writer) {
+		for i := 0; i < 3; i++ {
+			is.NoErr(wr.WriteSample([]byte{byte(i), 0}))
+		}
+	})
+
+	// 40 byte header, 24+16 bytes fmt, 24 byte data header, 6 bytes samples padded to 8
+	is.Equal(112, len(b))
+	is.Equal(guidW64Riff[:], b[:16])
+	is.Equal(uint64(112), binary.LittleEndian.Uint64(b[16:]))
+	is.Equal(uint64(30), binary.LittleEndian.Uint64(b[96:]))
+
+	rd, err := NewReader(bytes.NewReader(b), int64(len(b)))
+	is.NoErr(err)
+	got := rd.GetFile()
+	is.Equal(uint32(44100), got.SampleRate)
+	is.Equal(uint64(3), got.NumberOfSamples)
+	for i := 0; i < 3; i++ {
+		raw, err := rd.ReadRawSample()
+		is.NoErr(err)
+		is.Equal([]byte{byte(i), 0}, raw)
+	}
+}
+
+func TestWriteReadW64_float(t *testing.T) {
+	is := is.New(t)
+	meta := File{
+		Channels:        2,
+		SampleRate:      48000,
+		SignificantBits: 32,
+		AudioFormat:     AudioFormatIEEEFloat,
+	}
+	b := writeW64(t, meta, func(wr *Writer) {
+		is.NoErr(wr.WriteFloat32(0.5))
+		is.NoErr(wr.WriteFloat32(-0.5))
+	})
+
+	is.True(bytes.Contains(b, append(guidW64Fact[:], 32, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0)))
+
+	rd, err := NewReader(bytes.NewReader(b), int64(len(b)))
+	is.NoErr(err)
+	is.Equal(AudioFormatIEEEFloat, rd.GetAudioFormat())
+	v, err := rd.ReadFloat32()
+	is.NoErr(err)
+	is.Equal(float32(0.5), v)
+}
+
+func TestParseW64Headers_unknownChunk(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+
+	var body bytes.Buffer
+	binary.Write(&body, binary.LittleEndian, w64ChunkHeader{ID: GUID{'j', 'u', 'n', 'k'}, Size: 24 + 3})
+	body.Write([]byte{1, 2, 3, 0, 0, 0, 0, 0})
+	binary.Write(&body, binary.LittleEndian, w64ChunkHeader{ID: guidW64Fmt, Size: 24 + 16})
+	body.Write(testRiffChunkFmt[4:20])
+	binary.Write(&body, binary.LittleEndian, w64ChunkHeader{ID: guidW64Data, Size: 24 + 2})
+	body.Write([]byte{0x01, 0x01})
+
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, w64Header{
+		Riff: guidW64Riff,
+		Size: uint64(40 + body.Len()),
+		Wave: guidW64Wave,
+	})
+	b.Write(body.Bytes())
+
+	rd, err := NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	is.NoErr(err)
+	is.Equal(uint64(1), rd.GetSampleCount())
+	raw, err := rd.ReadRawSample()
+	is.NoErr(err)
+	is.Equal([]byte{1, 1}, raw)
+}
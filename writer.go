@@ -2,9 +2,11 @@ package wav
 
 import (
 	"bufio"
+	"bytes"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"math"
 	"os"
 )
 
@@ -20,74 +22,289 @@ type Writer struct {
 	options   File
 	sampleBuf *bufio.Writer
 
-	bytesWritten int
+	order        binary.ByteOrder // of the samples
+	unsigned     bool             // integer samples are offset binary
+	headerSize   int64            // offset of the first sample
+	framesPos    int64            // offset of the sample frame count in the header, 0 if there is none
+	bytesWritten int64            // number of sample bytes
+	align        int64            // the samples are padded to a multiple of align
+	trailer      []byte           // chunks following the samples
+
+	// finish corrects the sizes in the header of the container
+	finish func() error
 }
 
-// NewWriter creates a new WaveWriter and writes the header to it
+// NewWriter creates a new WaveWriter and writes the header to it.
+// The header reserves space with a JUNK chunk which is turned into a ds64 chunk
+// by Close, if the file grows beyond 4 GiB and has to become RF64.
+// Setting ByteOrder to binary.BigEndian produces a RIFX file, which can't be larger than 4 GiB.
 func (file File) NewWriter(out output) (wr *Writer, err error) {
-	if file.Channels != 1 {
-		err = fmt.Errorf("sorry, only mono currently")
-		return
+	if err = file.checkWAV(); err != nil {
+		return nil, err
+	}
+
+	var (
+		order binary.ByteOrder = binary.LittleEndian
+		magic                  = tokenRiff
+	)
+	switch file.ByteOrder {
+	case nil, binary.LittleEndian:
+	case binary.BigEndian:
+		order = binary.BigEndian
+		magic = tokenRifx
+	default:
+		return nil, fmt.Errorf("unsupported byte order %v", file.ByteOrder)
 	}
 
-	wr = &Writer{}
-	wr.output = out
-	wr.sampleBuf = bufio.NewWriter(out)
-	wr.options = file
+	wr = newWriter(out, file, order)
+	wr.finish = wr.finishRIFF
 
-	// write header when close to get correct number of samples
-	_, err = wr.Seek(12, os.SEEK_SET)
-	if err != nil {
-		return
+	// sizes are zero for now and get corrected on Close
+	var hdr bytes.Buffer
+	binary.Write(&hdr, order, riffHeader{
+		Ftype:       magic,
+		ChunkFormat: tokenWaveFormat,
+	})
+
+	hdr.Write(tokenJunk[:])
+	binary.Write(&hdr, order, uint32(28))
+	binary.Write(&hdr, order, riffChunkDS64{})
+
+	chunkFmt := file.chunkFmt(order)
+	hdr.Write(tokenChunkFmt[:])
+	binary.Write(&hdr, order, uint32(len(chunkFmt)))
+	hdr.Write(chunkFmt)
+
+	// non-PCM formats need a fact chunk
+	if file.AudioFormat != AudioFormatPCM {
+		hdr.Write(tokenFact[:])
+		binary.Write(&hdr, order, uint32(4))
+		wr.framesPos = int64(hdr.Len())
+		binary.Write(&hdr, order, uint32(0))
 	}
 
-	// fmt.Fprintf(wr, "%s", tokenChunkFmt)
-	n, err := wr.output.Write(tokenChunkFmt[:])
+	// metadata goes in front of the samples, except for copied chunks which followed them
+	front, back, err := file.metadata(order)
 	if err != nil {
-		return
+		return nil, err
 	}
-	wr.bytesWritten += n
+	hdr.Write(front)
+	wr.trailer = back
 
-	chunkFmt := riffChunkFmt{
-		LengthOfHeader: 16,
-		AudioFormat:    1,
-		NumChannels:    file.Channels,
-		SampleRate:     file.SampleRate,
-		BytesPerSec:    uint32(file.Channels) * file.SampleRate * uint32(file.SignificantBits) / 8,
-		BytesPerBloc:   file.SignificantBits / 8 * file.Channels,
-		BitsPerSample:  file.SignificantBits,
+	hdr.Write(tokenData[:])
+	binary.Write(&hdr, order, uint32(0))
+
+	return wr, wr.writeHeader(&hdr)
+}
+
+func newWriter(out output, file File, order binary.ByteOrder) *Writer {
+	return &Writer{
+		output:    out,
+		options:   file,
+		sampleBuf: bufio.NewWriter(out),
+		order:     order,
+		unsigned:  file.AudioFormat == AudioFormatPCM && file.SignificantBits <= 8,
+		align:     2,
 	}
+}
 
-	err = binary.Write(wr.output, binary.LittleEndian, chunkFmt)
-	if err != nil {
-		return
+// writeHeader writes hdr to the start of the output, the samples follow it
+func (w *Writer) writeHeader(hdr *bytes.Buffer) error {
+	w.headerSize = int64(hdr.Len())
+
+	if _, err := w.Seek(0, os.SEEK_SET); err != nil {
+		return err
 	}
-	wr.bytesWritten += 20 //sizeof riffChunkFmt
 
-	n, err = wr.output.Write(tokenData[:])
-	if err != nil {
-		return
+	_, err := hdr.WriteTo(w.output)
+	return err
+}
+
+// checkWAV validates the options for the WAV based writers and fills in the defaults
+func (file *File) checkWAV() error {
+	if file.Channels == 0 {
+		return fmt.Errorf("need at least one channel")
 	}
-	wr.bytesWritten += n
 
-	// leave space for the data size
-	_, err = wr.Seek(4, os.SEEK_CUR)
-	if err != nil {
-		return
+	if file.AudioFormat == 0 || file.AudioFormat == AudioFormatExtensible {
+		file.AudioFormat = AudioFormatPCM
+		if format, ok := file.SubFormat.AudioFormat(); ok {
+			file.AudioFormat = format
+		}
+	}
+
+	if file.ValidBits > file.SignificantBits {
+		return fmt.Errorf("ValidBits %d exceed SignificantBits %d", file.ValidBits, file.SignificantBits)
+	}
+
+	switch file.AudioFormat {
+	case AudioFormatPCM:
+		if file.SignificantBits < 8 {
+			return ErrNoBitsPerSample
+		}
+		if file.SignificantBits > 32 {
+			return ErrFormatNotSupported
+		}
+	case AudioFormatIEEEFloat:
+		if file.SignificantBits != 32 && file.SignificantBits != 64 {
+			return ErrFormatNotSupported
+		}
+	case AudioFormatALaw, AudioFormatMULaw:
+		if file.SignificantBits != 8 {
+			return ErrFormatNotSupported
+		}
+	default:
+		return ErrFormatNotSupported
+	}
+
+	return nil
+}
+
+// chunkFmt returns the body of the fmt chunk in the given byte order
+func (file File) chunkFmt(order binary.ByteOrder) []byte {
+	chunkFmt := riffChunkFmt{
+		AudioFormat:   file.AudioFormat,
+		NumChannels:   file.Channels,
+		SampleRate:    file.SampleRate,
+		BytesPerSec:   uint32(file.Channels) * file.SampleRate * uint32(file.containerBytes()),
+		BytesPerBloc:  uint16(file.containerBytes()) * file.Channels,
+		BitsPerSample: file.SignificantBits,
+	}
+
+	var b bytes.Buffer
+	switch {
+	case file.extensible():
+		chunkFmt.AudioFormat = AudioFormatExtensible
+		binary.Write(&b, order, chunkFmt)
+		binary.Write(&b, order, uint16(22))
+
+		ext := riffChunkFmtExtensible{
+			ValidBitsPerSample: file.ValidBits,
+			ChannelMask:        file.ChannelMask,
+			SubFormat:          SubFormatGUID(file.AudioFormat),
+		}
+		if ext.ValidBitsPerSample == 0 {
+			ext.ValidBitsPerSample = file.SignificantBits
+		}
+		binary.Write(&b, order, ext)
+	case file.AudioFormat == AudioFormatPCM:
+		binary.Write(&b, order, chunkFmt)
+	default:
+		// non-PCM formats carry a cbSize
+		binary.Write(&b, order, chunkFmt)
+		binary.Write(&b, order, uint16(0))
 	}
 
-	return
+	// LengthOfHeader is written as the chunk size
+	return b.Bytes()[4:]
 }
 
-// WriteInt32 writes the sample to the file using the binary package
+// containerBytes returns the number of bytes a sample occupies, odd bit depths are padded
+func (file File) containerBytes() int {
+	return (int(file.SignificantBits) + 7) / 8
+}
+
+// extensible reports whether the fmt chunk needs the WAVE_FORMAT_EXTENSIBLE layout.
+// This is the case for more than two channels and whenever the extensible fields are set
+func (file File) extensible() bool {
+	return file.Channels > 2 ||
+		(file.ValidBits != 0 && file.ValidBits != file.SignificantBits) ||
+		file.ChannelMask != 0 ||
+		file.SubFormat != GUID{}
+}
+
+// WriteInt32 writes the sample in the sample container of the file.
+// Like ReadSample returns them, samples range from -2^(n-1) to 2^(n-1)-1 for n valid bits.
+// A-law and μ-law files expect 16 bit linear samples and encode them
 func (w *Writer) WriteInt32(sample int32) error {
-	err := binary.Write(w.sampleBuf, binary.LittleEndian, sample)
-	if err != nil {
+	switch w.options.AudioFormat {
+	case AudioFormatALaw:
+		return w.writeByte(linearToALaw(clampInt16(sample)))
+	case AudioFormatMULaw:
+		return w.writeByte(linearToULaw(clampInt16(sample)))
+	case AudioFormatIEEEFloat:
+		return ErrSampleType
+	}
+
+	size := w.options.containerBytes()
+	if size > 4 {
+		return ErrFormatNotSupported
+	}
+
+	valid := uint(w.options.ValidBits)
+	if valid == 0 {
+		valid = uint(w.options.SignificantBits)
+	}
+
+	bits := 8 * uint(size)
+	u := uint32(sample) << (bits - valid)
+	if w.unsigned {
+		u ^= 1 << (bits - 1)
+	}
+
+	var b [4]byte
+	for i := 0; i < size; i++ {
+		shift := 8 * uint(i)
+		if w.order == binary.BigEndian {
+			shift = 8 * uint(size-1-i)
+		}
+		b[i] = byte(u >> shift)
+	}
+
+	n, err := w.sampleBuf.Write(b[:size])
+	w.bytesWritten += int64(n)
+	return err
+}
+
+func (w *Writer) writeByte(b byte) error {
+	if err := w.sampleBuf.WriteByte(b); err != nil {
 		return err
 	}
+	w.bytesWritten++
+	return nil
+}
+
+func clampInt16(sample int32) int16 {
+	if sample > math.MaxInt16 {
+		return math.MaxInt16
+	}
+	if sample < math.MinInt16 {
+		return math.MinInt16
+	}
+	return int16(sample)
+}
+
+// WriteFloat32 writes the sample to an IEEE float file
+func (w *Writer) WriteFloat32(sample float32) error {
+	if w.options.AudioFormat != AudioFormatIEEEFloat {
+		return ErrSampleType
+	}
+
+	if w.options.SignificantBits == 64 {
+		return w.WriteFloat64(float64(sample))
+	}
+
+	var b [4]byte
+	w.order.PutUint32(b[:], math.Float32bits(sample))
+	n, err := w.sampleBuf.Write(b[:])
+	w.bytesWritten += int64(n)
+	return err
+}
+
+// WriteFloat64 writes the sample to an IEEE float file
+func (w *Writer) WriteFloat64(sample float64) error {
+	if w.options.AudioFormat != AudioFormatIEEEFloat {
+		return ErrSampleType
+	}
 
-	w.bytesWritten += 4
+	if w.options.SignificantBits == 32 {
+		return w.WriteFloat32(float32(sample))
+	}
 
+	var b [8]byte
+	w.order.PutUint64(b[:], math.Float64bits(sample))
+	n, err := w.sampleBuf.Write(b[:])
+	w.bytesWritten += int64(n)
 	return err
 }
 
@@ -102,50 +319,134 @@ func (w *Writer) WriteSample(sample []byte) error {
 		return err
 	}
 
-	w.bytesWritten += n
+	w.bytesWritten += int64(n)
 
 	return nil
 }
 
 func (w *Writer) Write(data []byte) (int, error) {
-	n, err := w.output.Write(data)
-	w.bytesWritten += n
+	n, err := w.sampleBuf.Write(data)
+	w.bytesWritten += int64(n)
 	return n, err
 }
 
 // Close corrects the filesize information in the header
 func (w *Writer) Close() error {
+	// chunks are word aligned
+	if _, err := w.sampleBuf.Write(make([]byte, w.padding())); err != nil {
+		return err
+	}
+	if _, err := w.sampleBuf.Write(w.trailer); err != nil {
+		return err
+	}
+
 	if err := w.sampleBuf.Flush(); err != nil {
 		return err
 	}
 
+	if err := w.finish(); err != nil {
+		return err
+	}
+
+	return w.output.Close()
+}
+
+// patch overwrites the header field at pos with v
+func (w *Writer) patch(pos int64, order binary.ByteOrder, v interface{}) error {
+	if _, err := w.Seek(pos, os.SEEK_SET); err != nil {
+		return err
+	}
+	return binary.Write(w.output, order, v)
+}
+
+// padding returns the number of bytes needed to align the samples
+func (w *Writer) padding() int64 {
+	return (w.align - w.bytesWritten%w.align) % w.align
+}
+
+// finishRIFF writes the RIFF and data sizes, upgrading the file to RF64 if needed
+func (w *Writer) finishRIFF() error {
+	riffSize := w.headerSize - 8 + w.bytesWritten + w.padding() + int64(len(w.trailer))
+
+	frameSize := int64(w.options.containerBytes()) * int64(w.options.Channels)
+	ds64 := riffChunkDS64{
+		RiffSize:    uint64(riffSize),
+		DataSize:    uint64(w.bytesWritten),
+		SampleCount: uint64(w.bytesWritten / frameSize),
+	}
+	rf64 := riffSize > math.MaxUint32
+	if rf64 && w.order != binary.LittleEndian {
+		// there is no 64 bit variant of RIFX
+		return ErrInputToLarge
+	}
+
 	_, err := w.Seek(0, os.SEEK_SET)
 	if err != nil {
 		return err
 	}
 
 	header := riffHeader{
-		ChunkSize: uint32(w.bytesWritten + 8),
+		Ftype:       tokenRiff,
+		ChunkSize:   uint32(riffSize),
+		ChunkFormat: tokenWaveFormat,
+	}
+	if w.order == binary.BigEndian {
+		header.Ftype = tokenRifx
+	}
+	if rf64 {
+		header.Ftype = tokenRF64
+		header.ChunkSize = 0xFFFFFFFF
 	}
-	copy(header.Ftype[:], tokenRiff[:])
-	copy(header.ChunkFormat[:], tokenWaveFormat[:])
 
-	err = binary.Write(w.output, binary.LittleEndian, header)
+	err = binary.Write(w.output, w.order, header)
 	if err != nil {
 		return err
 	}
 
-	// write data chunk size
-	_, err = w.Seek(0x28, os.SEEK_SET)
-	if err != nil {
-		return err
+	if rf64 {
+		// turn the reserved JUNK chunk into ds64
+		_, err = w.output.Write(tokenDS64[:])
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Seek(4, os.SEEK_CUR)
+		if err != nil {
+			return err
+		}
+
+		err = binary.Write(w.output, w.order, ds64)
+		if err != nil {
+			return err
+		}
+	}
+
+	if w.framesPos != 0 {
+		// write number of sample frames
+		_, err = w.Seek(w.framesPos, os.SEEK_SET)
+		if err != nil {
+			return err
+		}
+
+		err = binary.Write(w.output, w.order, clampUint32(ds64.SampleCount))
+		if err != nil {
+			return err
+		}
 	}
 
-	// write chunk size
-	err = binary.Write(w.output, binary.LittleEndian, int32(w.bytesWritten))
+	// write data chunk size
+	_, err = w.Seek(w.headerSize-4, os.SEEK_SET)
 	if err != nil {
 		return err
 	}
 
-	return w.output.Close()
+	return binary.Write(w.output, w.order, clampUint32(ds64.DataSize))
+}
+
+// clampUint32 returns the 32 bit size field for v, which is 0xFFFFFFFF if the size moved to ds64
+func clampUint32(v uint64) uint32 {
+	if v > math.MaxUint32 {
+		return 0xFFFFFFFF
+	}
+	return uint32(v)
 }
@@ -2,6 +2,7 @@ package wav
 
 import (
 	"bytes"
+	"io"
 	"io/ioutil"
 	"os"
 	"testing"
@@ -29,7 +30,7 @@ func TestNewWriter_Header(t *testing.T) {
 
 	b, err := ioutil.ReadAll(f)
 	is.NoErr(err)
-	is.Equal(len(b), 44)
+	is.Equal(len(b), 80)
 
 	is.True(bytes.Contains(b, riff))
 	is.True(bytes.Contains(b, wave))
@@ -56,7 +57,7 @@ func TestNewWriter_1Sample(t *testing.T) {
 
 	b, err := ioutil.ReadAll(f)
 	is.NoErr(err)
-	is.Equal(len(b), 46)
+	is.Equal(len(b), 82)
 
 	is.True(bytes.Contains(b, riff))
 	is.True(bytes.Contains(b, wave))
@@ -105,7 +106,7 @@ func benchWriteInt(sample int32, samples int, b *testing.B) {
 	b.SetBytes(int64(2 * samples))
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
-		for i := 0; i < samples/2; i++ {
+		for i := 0; i < samples; i++ {
 			if err := wr.WriteInt32(sample); err != nil {
 				b.Fatal(err)
 			}
@@ -120,3 +121,122 @@ func BenchmarkWriteInt32_10thSec(b *testing.B)  { benchWriteInt(0, 44100/10, b)
 func BenchmarkWriteInt32_HalfSec(b *testing.B)  { benchWriteInt(0, 44100/2, b) }
 func BenchmarkWriteInt32_1Sec(b *testing.B)     { benchWriteInt(0, 44100, b) }
 func BenchmarkWriteInt32_2Sec(b *testing.B)     { benchWriteInt(0, 2*44100, b) }
+
+func TestNewWriter_FloatNeedsFact(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	f, err := ioutil.TempFile("", "wavPkgtest")
+	is.NoErr(err)
+	meta := File{
+		SampleRate:      44100,
+		Channels:        1,
+		SignificantBits: 32,
+		AudioFormat:     AudioFormatIEEEFloat,
+	}
+	wr, err := meta.NewWriter(f)
+	is.NoErr(err)
+	is.NoErr(wr.WriteFloat32(0.5))
+	is.Nil(wr.Close())
+
+	f, err = os.Open(f.Name())
+	is.NoErr(err)
+
+	b, err := ioutil.ReadAll(f)
+	is.NoErr(err)
+	is.Equal(len(b), 98)
+	is.True(bytes.Contains(b, []byte("fact\x04\x00\x00\x00\x01\x00\x00\x00")))
+	is.True(bytes.Contains(b, []byte("data\x04\x00\x00\x00")))
+
+	is.Nil(os.Remove(f.Name()))
+}
+
+func TestNewWriter_FloatBits(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	meta := File{
+		SampleRate:      44100,
+		Channels:        1,
+		SignificantBits: 24,
+		AudioFormat:     AudioFormatIEEEFloat,
+	}
+	_, err := meta.NewWriter(nil)
+	is.Equal(ErrFormatNotSupported, err)
+}
+
+func TestNewWriter_PCMBits(t *testing.T) {
+	t.Parallel()
+	is := is.New(t)
+	for _, bits := range []uint16{33, 64, 65535} {
+		meta := File{
+			SampleRate:      8000,
+			Channels:        1,
+			SignificantBits: bits,
+		}
+		_, err := meta.NewWriter(nil)
+		is.Equal(ErrFormatNotSupported, err)
+	}
+}
+
+// sparseOutput keeps the head of the file in memory and only counts the rest
+type sparseOutput struct {
+	head      [512]byte
+	pos, size int64
+}
+
+func (s *sparseOutput) Write(p []byte) (int, error) {
+	if s.pos < int64(len(s.head)) {
+		copy(s.head[s.pos:], p)
+	}
+	s.pos += int64(len(p))
+	if s.pos > s.size {
+		s.size = s.pos
+	}
+	return len(p), nil
+}
+
+func (s *sparseOutput) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		s.pos = offset
+	case io.SeekCurrent:
+		s.pos += offset
+	case io.SeekEnd:
+		s.pos = s.size + offset
+	}
+	return s.pos, nil
+}
+
+func (s *sparseOutput) Close() error { return nil }
+
+func TestNewWriter_RF64(t *testing.T) {
+	if testing.Short() {
+		t.Skip("writes more than 4 GiB")
+	}
+	t.Parallel()
+	is := is.New(t)
+
+	meta := File{
+		SampleRate:      48000,
+		Channels:        2,
+		SignificantBits: 16,
+	}
+	out := new(sparseOutput)
+	wr, err := meta.NewWriter(out)
+	is.NoErr(err)
+
+	const dataSize = 4<<30 + 1<<20
+	chunk := make([]byte, 1<<20)
+	for i := 0; i < dataSize/len(chunk); i++ {
+		_, err = wr.Write(chunk)
+		is.NoErr(err)
+	}
+	is.NoErr(wr.Close())
+	is.Equal(int64(80+dataSize), out.size)
+
+	is.Equal([]byte("RF64\xff\xff\xff\xffWAVEds64"), out.head[:16])
+
+	rd, err := NewReader(bytes.NewReader(out.head[:]), out.size)
+	is.NoErr(err)
+	is.Equal(uint64(dataSize/2), rd.GetSampleCount())
+	is.Equal(uint64(dataSize), rd.GetFile().SoundSize)
+}